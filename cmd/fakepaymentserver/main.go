@@ -0,0 +1,37 @@
+// Command fakepaymentserver adalah payment server palsu untuk development & testing lokal.
+//
+//	go run ./cmd/fakepaymentserver -addr :8080 -scenario scripts/fake_payment_scenario.yaml
+//
+// Endpoint: GET /healthz, GET /api/v1/payments/{id} (format sama dengan payment server asli),
+// POST /admin/payments/{id}/status {"status":"PAID"}, POST /admin/payments/{id}/reset, GET /admin/payments.
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"os"
+)
+
+func main() {
+	addr := flag.String("addr", ":8080", "listen address")
+	scenarioPath := flag.String("scenario", "", "scenario file (.json / .yaml); empty = every payment PENDING 3x then PAID")
+	apiKey := flag.String("api-key", os.Getenv("FAKE_PAYMENT_SERVER_API_KEY"), "require this bearer token on payment requests (empty = no auth)")
+	flag.Parse()
+
+	scenario := defaultScenario()
+	if *scenarioPath != "" {
+		loaded, err := loadScenario(*scenarioPath)
+		if err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		scenario = loaded
+		log.Printf("📜 Loaded scenario %s (%d payments)", *scenarioPath, len(scenario.Payments))
+	}
+
+	server := newFakeServer(scenario, *apiKey)
+	log.Printf("🟢 Fake payment server running on %s", *addr)
+	if err := http.ListenAndServe(*addr, server.routes()); err != nil {
+		log.Fatalf("❌ Server failed: %v", err)
+	}
+}
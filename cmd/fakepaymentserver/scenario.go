@@ -0,0 +1,89 @@
+package main
+
+import (
+	"beta-payment-api-client/internal/valueobject"
+	"encoding/json"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Step satu tahap perilaku payment. Tahap dipakai Times kali lalu lanjut ke tahap berikutnya;
+// Times 0 = dipakai terus. Setelah tahap terakhir habis, tahap terakhir diulang.
+type Step struct {
+	Status     string               `json:"status,omitempty"`      // status payment di body response (default PENDING)
+	Times      int                  `json:"times,omitempty"`       // jumlah call untuk tahap ini
+	HTTPStatus int                  `json:"http_status,omitempty"` // selain 200 → body error, mis. 500 / 404 / 429
+	RetryAfter int                  `json:"retry_after,omitempty"` // header Retry-After (detik) untuk 429 / 503
+	Malformed  bool                 `json:"malformed,omitempty"`   // body JSON rusak
+	Latency    valueobject.Duration `json:"latency"`               // tambahan latency untuk tahap ini
+}
+
+// Behaviour perilaku satu payment.
+type Behaviour struct {
+	Latency   valueobject.Duration `json:"latency"`
+	ErrorRate float64              `json:"error_rate,omitempty"` // peluang 0..1 response 500 acak
+	Steps     []Step               `json:"steps"`
+}
+
+// Scenario perilaku per payment ID; payment yang tidak terdaftar memakai Default (nil → 404).
+type Scenario struct {
+	Default  *Behaviour           `json:"default,omitempty"`
+	Payments map[string]Behaviour `json:"payments"`
+}
+
+// defaultScenario tanpa file: semua payment PENDING 3x lalu PAID.
+func defaultScenario() *Scenario {
+	return &Scenario{
+		Default: &Behaviour{Steps: []Step{
+			{Status: "PENDING", Times: 3},
+			{Status: "PAID"},
+		}},
+		Payments: map[string]Behaviour{},
+	}
+}
+
+// loadScenario membaca scenario dari file JSON atau YAML (berdasarkan ekstensi).
+// YAML dikonversi ke JSON dulu supaya tag json + valueobject.Duration ("2s") tetap dipakai.
+func loadScenario(path string) (*Scenario, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext == ".yaml" || ext == ".yml" {
+		var document any
+		if err := yaml.Unmarshal(raw, &document); err != nil {
+			return nil, fmt.Errorf("parse scenario %s: %w", path, err)
+		}
+		if raw, err = json.Marshal(document); err != nil {
+			return nil, fmt.Errorf("parse scenario %s: %w", path, err)
+		}
+	}
+
+	scenario := &Scenario{}
+	if err := json.Unmarshal(raw, scenario); err != nil {
+		return nil, fmt.Errorf("parse scenario %s: %w", path, err)
+	}
+	if scenario.Payments == nil {
+		scenario.Payments = map[string]Behaviour{}
+	}
+	return scenario, nil
+}
+
+// step memilih tahap untuk call ke-n (mulai 0).
+func (b Behaviour) step(n int) Step {
+	if len(b.Steps) == 0 {
+		return Step{Status: "PENDING"}
+	}
+	for _, s := range b.Steps {
+		if s.Times <= 0 || n < s.Times {
+			return s
+		}
+		n -= s.Times
+	}
+	return b.Steps[len(b.Steps)-1]
+}
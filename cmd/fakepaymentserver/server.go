@@ -0,0 +1,203 @@
+package main
+
+import (
+	"beta-payment-api-client/internal/delivery/http/router"
+	"beta-payment-api-client/internal/dto"
+	"beta-payment-api-client/internal/valueobject"
+	"encoding/json"
+	"log"
+	"math/big"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// paymentState state runtime satu payment.
+type paymentState struct {
+	Calls     int       `json:"calls"`
+	Forced    string    `json:"forced_status,omitempty"` // di-set lewat admin endpoint; menimpa scenario
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type fakeServer struct {
+	scenario *Scenario
+	apiKey   string
+
+	mu       sync.Mutex
+	payments map[string]*paymentState
+}
+
+func newFakeServer(scenario *Scenario, apiKey string) *fakeServer {
+	return &fakeServer{
+		scenario: scenario,
+		apiKey:   apiKey,
+		payments: map[string]*paymentState{},
+	}
+}
+
+func (s *fakeServer) routes() http.Handler {
+	r := router.NewRouter()
+	r.Handle(http.MethodGet, "/healthz", s.health)
+	r.Handle(http.MethodGet, "/api/v1/payments/{id}", s.getPayment)
+	// ⚠️ Router mencocokkan prefix, route yang lebih spesifik didaftarkan lebih dulu
+	r.Handle(http.MethodPost, "/admin/payments/{id}/status", s.setStatus)
+	r.Handle(http.MethodPost, "/admin/payments/{id}/reset", s.resetPayment)
+	r.Handle(http.MethodGet, "/admin/payments", s.listPayments)
+	return r
+}
+
+func (s *fakeServer) health(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// getPayment meniru GET /api/v1/payments/{id} payment server asli.
+func (s *fakeServer) getPayment(w http.ResponseWriter, r *http.Request) {
+	if s.apiKey != "" && r.Header.Get("Authorization") != "Bearer "+s.apiKey {
+		writeEnvelope(w, http.StatusUnauthorized, "failed", "Unauthorized", nil)
+		return
+	}
+
+	id := router.GetParam(r, "id")
+	behaviour, known := s.behaviour(id)
+	if !known {
+		writeEnvelope(w, http.StatusNotFound, "failed", "Payment not found", nil)
+		return
+	}
+
+	s.mu.Lock()
+	state, ok := s.payments[id]
+	if !ok {
+		now := time.Now()
+		state = &paymentState{CreatedAt: now}
+		s.payments[id] = state
+	}
+	step := behaviour.step(state.Calls)
+	if state.Forced != "" {
+		step = Step{Status: state.Forced}
+	}
+	state.Calls++
+	state.UpdatedAt = time.Now()
+	calls, createdAt, updatedAt := state.Calls, state.CreatedAt, state.UpdatedAt
+	s.mu.Unlock()
+
+	time.Sleep(behaviour.Latency.Duration + step.Latency.Duration)
+
+	switch {
+	case behaviour.ErrorRate > 0 && rand.Float64() < behaviour.ErrorRate:
+		log.Printf("💥 %s call #%d → random 500", id, calls)
+		writeEnvelope(w, http.StatusInternalServerError, "failed", "Internal Server Error", nil)
+	case step.HTTPStatus != 0 && step.HTTPStatus != http.StatusOK:
+		log.Printf("💥 %s call #%d → %d", id, calls, step.HTTPStatus)
+		if step.RetryAfter > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(step.RetryAfter))
+		}
+		writeEnvelope(w, step.HTTPStatus, "failed", http.StatusText(step.HTTPStatus), nil)
+	case step.Malformed:
+		log.Printf("🧨 %s call #%d → malformed body", id, calls)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"status":"success","data":{"id":"` + id + `","status":`))
+	default:
+		status := step.Status
+		if status == "" {
+			status = "PENDING"
+		}
+		log.Printf("📦 %s call #%d → %s", id, calls, status)
+		writeEnvelope(w, http.StatusOK, "success", "Success Get Payment by ID", dto.PaymentData{
+			ID:        id,
+			Amount:    valueobject.BigFloat{Float: big.NewFloat(0)},
+			Status:    status,
+			CreatedAt: createdAt,
+			UpdatedAt: updatedAt,
+		})
+	}
+}
+
+// setStatus memaksa status payment saat runtime, mis. {"status":"PAID"}.
+func (s *fakeServer) setStatus(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Status string `json:"status"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Status == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": `body must be {"status":"..."}`})
+		return
+	}
+
+	id := router.GetParam(r, "id")
+	s.mu.Lock()
+	state, ok := s.payments[id]
+	if !ok {
+		state = &paymentState{CreatedAt: time.Now()}
+		s.payments[id] = state
+	}
+	state.Forced = strings.ToUpper(body.Status)
+	state.UpdatedAt = time.Now()
+	snapshot := *state
+	s.mu.Unlock()
+
+	// Payment di luar scenario tetap bisa di-flip
+	if _, known := s.behaviour(id); !known {
+		s.mu.Lock()
+		s.scenario.Payments[id] = Behaviour{}
+		s.mu.Unlock()
+	}
+
+	log.Printf("🔀 %s flipped to %s", id, snapshot.Forced)
+	writeJSON(w, http.StatusOK, snapshot)
+}
+
+// resetPayment menghapus status paksa + hitungan call; scenario mulai dari tahap pertama lagi.
+func (s *fakeServer) resetPayment(w http.ResponseWriter, r *http.Request) {
+	id := router.GetParam(r, "id")
+	s.mu.Lock()
+	delete(s.payments, id)
+	s.mu.Unlock()
+
+	log.Printf("♻️ %s reset", id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *fakeServer) listPayments(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	payments := make(map[string]paymentState, len(s.payments))
+	for id, state := range s.payments {
+		payments[id] = *state
+	}
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, payments)
+}
+
+func (s *fakeServer) behaviour(id string) (Behaviour, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if behaviour, ok := s.scenario.Payments[id]; ok {
+		return behaviour, true
+	}
+	if s.scenario.Default != nil {
+		return *s.scenario.Default, true
+	}
+	return Behaviour{}, false
+}
+
+// writeEnvelope menulis response dengan format dto.GetPaymentByIDResponse.
+func writeEnvelope(w http.ResponseWriter, code int, status, message string, data any) {
+	writeJSON(w, code, map[string]any{
+		"status":  status,
+		"entity":  "payments",
+		"state":   "getPaymentByID",
+		"message": message,
+		"data":    data,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, code int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(body)
+}
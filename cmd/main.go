@@ -18,52 +18,111 @@ import (
 	"beta-payment-api-client/config"
 	_ "beta-payment-api-client/docs"
 	deliveryHttp "beta-payment-api-client/internal/delivery/http"
+	"beta-payment-api-client/internal/entity"
 	pkgDatabase "beta-payment-api-client/internal/pkg/database"
+	"beta-payment-api-client/internal/pkg/healthcheck"
 	pkgKafka "beta-payment-api-client/internal/pkg/kafka"
 	pkgLogger "beta-payment-api-client/internal/pkg/logger"
+	"beta-payment-api-client/internal/pkg/payment_provider"
 	pkgPaymentServer "beta-payment-api-client/internal/pkg/payment_server"
+	"beta-payment-api-client/internal/pkg/ratelimit"
+	"beta-payment-api-client/internal/pkg/redact"
 	pkgRedis "beta-payment-api-client/internal/pkg/redis"
+	pkgScheduler "beta-payment-api-client/internal/pkg/scheduler"
 	"beta-payment-api-client/internal/repository"
 	"beta-payment-api-client/internal/usecase"
+	"beta-payment-api-client/internal/valueobject"
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"github.com/google/uuid"
 	"github.com/joho/godotenv"
+	"github.com/redis/go-redis/v9"
 	"github.com/rs/zerolog"
+	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
-	"time"
 )
 
 func main() {
 	_ = godotenv.Load()
 	cfg := config.LoadConfig()
-	logger := pkgLogger.InitLoggerWithTelemetry(cfg)
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("❌ Invalid config: %v", err)
+	}
+	// Redactor dibuat sebelum logger: aturan invalid → berhenti sebelum ada log yang bocor
+	redactor, err := newRedactor(cfg)
+	if err != nil {
+		log.Fatalf("❌ Invalid redaction rules: %v", err)
+	}
+	logger := pkgLogger.InitLoggerWithTelemetry(cfg, redactor)
+
+	// Subcommand: go run cmd/main.go replay <check-log-id>
+	if len(os.Args) > 1 && os.Args[1] == "replay" {
+		os.Exit(runReplay(cfg, redactor, logger, os.Args[2:]))
+	}
 
 	postgresClient := pkgDatabase.NewPostgresClient(cfg, logger)
 	db := postgresClient.InitPostgresDB()
 	redisClient := pkgRedis.NewRedisClient(cfg, logger).InitRedis()
 	kafkaProducer := pkgKafka.NewKafkaProducerClient(cfg, logger).InitKafkaProducer()
 	kafkaConsumer := pkgKafka.NewKafkaConsumerClient(cfg, logger).InitKafkaConsumer()
-	paymentServerClient := pkgPaymentServer.NewPaymentServerClient(cfg, logger)
-
-	err := paymentServerClient.InitPaymentServer()
+	paymentServerLimiter := newPaymentServerLimiter(cfg, redisClient, pkgPaymentServer.DefaultProviderName, cfg.PaymentServerRateLimit, cfg.PaymentServerRateBurst, logger)
+	paymentServerClient, err := pkgPaymentServer.NewPaymentServerClient(cfg, paymentServerLimiter, logger)
 	if err != nil {
-		logger.Fatal().Err(err).Msg("Cannot reach payment server")
+		logger.Fatal().Err(err).Msg("❌ Invalid payment server config")
+	}
+
+	// Payment server down sementara → tetap start (not ready di /readyz); circuit breaker menahan polling
+	if err := paymentServerClient.InitPaymentServer(); err != nil {
+		logger.Warn().Err(err).Msg("‼️ Cannot reach payment server, starting in degraded mode")
+	}
+	paymentProviders := loadPaymentProviders(cfg, paymentServerClient, redisClient, logger)
+	statusMappings := loadStatusMappings(cfg, paymentProviders, logger)
+
+	paymentRecordRepo := repository.NewPaymentRecordRepository(redisClient, kafkaProducer, kafkaConsumer, db, cfg.KafkaTopicPaymentSuccess, cfg.KafkaTopicPaymentTimeout, logger)
+	checkLogWriter := newCheckLogWriter(cfg, db, logger)
+	paymentRecordCheckLogRepo := repository.NewPaymentRecordCheckLogRepository(db, checkLogWriter, redactor, logger)
+	paymentRecordCheckLogPartitionRepo := repository.NewPaymentRecordCheckLogPartitionRepository(db, logger)
+
+	pollingPolicies := loadPollingPolicies(cfg, logger)
+	pollingScheduler := pkgScheduler.NewScheduler(cfg.PollingWorkers, cfg.PollingQueueSize, logger)
+	pollingLease := usecase.PollingLeaseConfig{
+		InstanceID: cfg.InstanceID,
+		TTL:        cfg.PollingLeaseTTL,
+		Heartbeat:  cfg.PollingLeaseHeartbeat,
 	}
+	paymentRecordUC := usecase.NewPaymentRecordUseCase(paymentRecordRepo, paymentRecordCheckLogRepo, paymentProviders, statusMappings, pollingPolicies, pollingScheduler, pollingLease, db, logger)
+	logger.Info().Msgf("🪪 Instance ID: %s", cfg.InstanceID)
+	checkLogReplayUC := usecase.NewCheckLogReplayUseCase(paymentRecordRepo, paymentRecordCheckLogRepo, paymentProviders, redactor, logger)
+	checkLogRetentionUC := usecase.NewCheckLogRetentionUseCase(paymentRecordCheckLogPartitionRepo, loadCheckLogRetention(cfg, logger), logger)
 
-	paymentRecordRepo := repository.NewPaymentRecordRepository(redisClient, kafkaProducer, kafkaConsumer, db, cfg.PaymentServerAPIKey, cfg.KafkaTopicPaymentSuccess)
-	paymentRecordCheckLogRepo := repository.NewPaymentRecordCheckLogRepository(db, logger)
-	paymentRecordUC := usecase.NewPaymentRecordUseCase(paymentRecordRepo, paymentRecordCheckLogRepo, db, logger)
+	// Root context: dibatalkan saat SIGINT / SIGTERM
+	rootCtx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
-	// Start Kafka consumer
-	_ = paymentRecordUC.RestorePollingTasks(context.Background())
-	_ = paymentRecordUC.StartConsumer(context.Background())
+	// Readiness: cek dependency berkala untuk /readyz
+	readiness := newReadinessChecker(cfg, db, redisClient, kafkaProducer, paymentProviders, logger)
+	readiness.Start(rootCtx)
+
+	// Start polling scheduler + lease keeper + Kafka consumer
+	_ = paymentRecordUC.StartControlListener(rootCtx)
+	_ = paymentRecordUC.StartScheduler(rootCtx)
+	_ = paymentRecordUC.RestorePollingTasks(rootCtx)
+	_ = paymentRecordUC.StartLeaseKeeper(rootCtx)
+	_ = paymentRecordUC.StartConsumer(rootCtx)
+
+	// Partisi bulanan + retention check log (hanya satu replica per putaran)
+	if err := checkLogRetentionUC.Start(rootCtx); err != nil {
+		logger.Error().Err(err).Msg("❌ Failed to start check log retention")
+	}
 
 	// ====== Update dari sini
-	handler := deliveryHttp.SetupHandler(paymentRecordUC, logger)
+	handler := deliveryHttp.SetupHandler(paymentRecordUC, checkLogReplayUC, paymentProviders, readiness, logger)
 
 	// HTTP server config
 	server := &http.Server{
@@ -78,23 +137,38 @@ func main() {
 		}
 	}()
 
-	// Setup signal listener
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
+	// Tunggu signal
+	<-rootCtx.Done()
+	stop()
 
 	logger.Info().Msgf("🛑 Gracefully shutting down server...")
 
-	// Graceful shutdown context
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	// Graceful shutdown context: satu deadline untuk seluruh urutan shutdown
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
 	defer cancel()
 
-	// Shutdown HTTP server
+	// 1) Stop terima request baru
 	if err := server.Shutdown(ctx); err != nil {
-		logger.Fatal().Err(err).Msgf("❌ Server shutdown failed: %v", err)
+		logger.Error().Err(err).Msgf("❌ Server shutdown failed: %v", err)
 	}
 
-	// ✅ Close PostgreSQL DB
+	// 2) Tunggu worker polling (termasuk antri check log) + Kafka consumer selesai
+	if err := paymentRecordUC.Shutdown(ctx); err != nil {
+		logger.Error().Err(err).Msgf("❌ Polling shutdown failed: %v", err)
+	}
+
+	if err := checkLogRetentionUC.Shutdown(ctx); err != nil {
+		logger.Error().Err(err).Msgf("❌ Check log retention shutdown failed: %v", err)
+	}
+
+	// 3) Flush check log yang masih di buffer sebelum PostgreSQL ditutup
+	if err := checkLogWriter.Close(ctx); err != nil {
+		logger.Error().Err(err).Msgf("❌ Check log writer flush failed: %v", err)
+	}
+
+	// 4) Tutup Kafka reader & writer, Redis, lalu PostgreSQL
+	closeKafka(kafkaConsumer, kafkaProducer, logger)
+	closeRedis(redisClient, logger)
 	closePostgres(db, logger)
 
 	logger.Info().Msgf("✅ Server shutdown completed.")
@@ -111,6 +185,260 @@ func main() {
 	//select {} // block
 }
 
+// runReplay mengirim ulang request dari satu check log dan mencetak diff response lama vs baru sebagai JSON.
+// Hanya butuh PostgreSQL (+ Redis kalau rate limit memakai backend redis); polling & Kafka tidak dijalankan.
+func runReplay(cfg *config.AppConfig, redactor *redact.Redactor, logger zerolog.Logger, args []string) int {
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: go run cmd/main.go replay <check-log-id>")
+		return 2
+	}
+	checkLogID, err := uuid.Parse(args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid check log ID %q: %v\n", args[0], err)
+		return 2
+	}
+
+	postgresClient := pkgDatabase.NewPostgresClient(cfg, logger)
+	db := postgresClient.InitPostgresDB()
+	defer closePostgres(db, logger)
+
+	var redisClient *redis.Client
+	if cfg.PaymentServerRateBackend == ratelimit.BackendRedis {
+		redisClient = pkgRedis.NewRedisClient(cfg, logger).InitRedis()
+		defer closeRedis(redisClient, logger)
+	}
+
+	limiter := newPaymentServerLimiter(cfg, redisClient, pkgPaymentServer.DefaultProviderName, cfg.PaymentServerRateLimit, cfg.PaymentServerRateBurst, logger)
+	paymentServerClient, err := pkgPaymentServer.NewPaymentServerClient(cfg, limiter, logger)
+	if err != nil {
+		logger.Error().Err(err).Msg("❌ Invalid payment server config")
+		return 1
+	}
+	paymentProviders := loadPaymentProviders(cfg, paymentServerClient, redisClient, logger)
+
+	// Replay tidak menulis check log / publish Kafka → writer & Kafka tidak dibutuhkan
+	paymentRecordRepo := repository.NewPaymentRecordRepository(redisClient, nil, nil, db, cfg.KafkaTopicPaymentSuccess, cfg.KafkaTopicPaymentTimeout, logger)
+	paymentRecordCheckLogRepo := repository.NewPaymentRecordCheckLogRepository(db, nil, redactor, logger)
+	checkLogReplayUC := usecase.NewCheckLogReplayUseCase(paymentRecordRepo, paymentRecordCheckLogRepo, paymentProviders, redactor, logger)
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.PaymentServerTimeout+cfg.ShutdownTimeout)
+	defer cancel()
+	replay, err := checkLogReplayUC.Replay(ctx, checkLogID)
+	if err != nil {
+		logger.Error().Err(err).Msgf("❌ Failed to replay check log %s", checkLogID)
+		return 1
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(replay); err != nil {
+		logger.Error().Err(err).Msg("❌ Failed to print replay result")
+		return 1
+	}
+	return 0
+}
+
+func loadPollingPolicies(cfg *config.AppConfig, logger zerolog.Logger) entity.PollingPolicySet {
+	defaultPolicy := entity.PollingPolicy{
+		InitialDelay:    valueobject.Duration{Duration: cfg.PollingInitialDelay},
+		Multiplier:      cfg.PollingMultiplier,
+		MaxDelay:        valueobject.Duration{Duration: cfg.PollingMaxDelay},
+		Jitter:          cfg.PollingJitter,
+		BoostResetDelay: valueobject.Duration{Duration: cfg.PollingBoostResetDelay},
+		MaxAttempts:     cfg.PollingMaxAttempts,
+		Timeout:         valueobject.Duration{Duration: cfg.PollingTimeout},
+	}
+	if err := defaultPolicy.Validate(); err != nil {
+		logger.Fatal().Err(err).Msg("❌ Invalid default polling policy")
+	}
+
+	tagPolicies, err := entity.ParsePollingPolicyOverrides(cfg.PollingTagPolicies)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("❌ Invalid polling tag policies")
+	}
+	for tag, override := range tagPolicies {
+		if err := defaultPolicy.Apply(&override).Validate(); err != nil {
+			logger.Fatal().Err(err).Msgf("❌ Invalid polling policy for tag %q", tag)
+		}
+	}
+
+	return entity.PollingPolicySet{Default: defaultPolicy, Tags: tagPolicies}
+}
+
+// newRedactor membaca aturan redaksi tambahan (di atas default):
+// REDACT_HEADERS & REDACT_JSON_PATHS dipisah koma, REDACT_PATTERNS JSON {"nama": "regex"}.
+func newRedactor(cfg *config.AppConfig) (*redact.Redactor, error) {
+	redactCfg := redact.Config{}
+	if cfg.RedactHeaders != "" {
+		redactCfg.Headers = strings.Split(cfg.RedactHeaders, ",")
+	}
+	if cfg.RedactJSONPaths != "" {
+		redactCfg.JSONPaths = strings.Split(cfg.RedactJSONPaths, ",")
+	}
+	if cfg.RedactPatterns != "" {
+		if err := json.Unmarshal([]byte(cfg.RedactPatterns), &redactCfg.Patterns); err != nil {
+			return nil, fmt.Errorf("parse REDACT_PATTERNS: %w", err)
+		}
+	}
+	return redact.New(redactCfg)
+}
+
+// newCheckLogWriter membuat writer check log asynchronous; policy overflow selain "drop" / "block" → fatal.
+func newCheckLogWriter(cfg *config.AppConfig, db *sql.DB, logger zerolog.Logger) *repository.CheckLogWriter {
+	switch cfg.CheckLogOverflow {
+	case repository.CheckLogOverflowDrop, repository.CheckLogOverflowBlock:
+	default:
+		logger.Fatal().Msgf("❌ Invalid check log overflow policy %q (drop|block)", cfg.CheckLogOverflow)
+	}
+	logger.Info().Msgf("📝 Check log writer: buffer %d, batch %d, flush every %s, overflow %s",
+		cfg.CheckLogBufferSize, cfg.CheckLogBatchSize, cfg.CheckLogFlushInterval, cfg.CheckLogOverflow)
+	return repository.NewCheckLogWriter(db, repository.CheckLogWriterConfig{
+		BufferSize:    cfg.CheckLogBufferSize,
+		BatchSize:     cfg.CheckLogBatchSize,
+		FlushInterval: cfg.CheckLogFlushInterval,
+		Overflow:      cfg.CheckLogOverflow,
+	}, logger)
+}
+
+// loadCheckLogRetention membaca konfigurasi retention check log; mode selain "drop" / "detach" → fatal.
+func loadCheckLogRetention(cfg *config.AppConfig, logger zerolog.Logger) usecase.CheckLogRetentionConfig {
+	retention := usecase.CheckLogRetentionConfig{
+		RetentionDays:          cfg.CheckLogRetentionDays,
+		FinalizedRetentionDays: cfg.CheckLogArchiveDays,
+		PartitionsAhead:        cfg.CheckLogPartitionsAhead,
+		Interval:               cfg.CheckLogRetentionEvery,
+	}
+	switch cfg.CheckLogRetentionMode {
+	case "drop":
+	case "detach":
+		retention.Detach = true
+	default:
+		logger.Fatal().Msgf("❌ Invalid check log retention mode %q (drop|detach)", cfg.CheckLogRetentionMode)
+	}
+	if retention.RetentionDays <= 0 {
+		logger.Warn().Msg("‼️ Check log retention disabled, check logs are kept forever")
+	} else {
+		logger.Info().Msgf("🗄️ Check log retention: %d days (%s), finalized payments' last attempt: %d days",
+			retention.RetentionDays, cfg.CheckLogRetentionMode, retention.FinalizedRetentionDays)
+	}
+	return retention
+}
+
+// newPaymentServerLimiter membuat token bucket untuk semua request ke satu provider.
+// Backend "redis" membagi satu bucket ke semua replica; selain itu bucket per instance.
+func newPaymentServerLimiter(cfg *config.AppConfig, redisClient *redis.Client, provider string, rate float64, burst int, logger zerolog.Logger) ratelimit.Limiter {
+	if rate <= 0 {
+		logger.Warn().Msgf("‼️ Rate limit for %s disabled", provider)
+		return ratelimit.NewLocalLimiter(0, burst)
+	}
+
+	switch cfg.PaymentServerRateBackend {
+	case ratelimit.BackendRedis:
+		logger.Info().Msgf("🚦 Rate limit for %s: %.2f req/s, burst %d (redis, shared)", provider, rate, burst)
+		return ratelimit.NewRedisLimiter(redisClient, "rate_limit:"+provider, rate, burst, logger)
+	case ratelimit.BackendMemory:
+	default:
+		logger.Warn().Msgf("‼️ Unknown rate limit backend %q, using %s", cfg.PaymentServerRateBackend, ratelimit.BackendMemory)
+	}
+	logger.Info().Msgf("🚦 Rate limit for %s: %.2f req/s, burst %d (memory, per instance)", provider, rate, burst)
+	return ratelimit.NewLocalLimiter(rate, burst)
+}
+
+// loadPaymentProviders mendaftarkan payment server bawaan (default) + PSP dari PAYMENT_PROVIDERS,
+// lalu routing tag dari PAYMENT_PROVIDER_TAGS. Konfigurasi invalid → fatal, supaya payment tidak di-poll ke PSP yang salah.
+func loadPaymentProviders(cfg *config.AppConfig, defaultProvider *pkgPaymentServer.PaymentServerClient, redisClient *redis.Client, logger zerolog.Logger) *payment_provider.Registry {
+	registry := payment_provider.NewRegistry(defaultProvider)
+
+	providers, err := pkgPaymentServer.ParseProviderConfigs(cfg.PaymentProviders)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("❌ Invalid payment providers")
+	}
+	defaults := pkgPaymentServer.DefaultClientConfig(cfg)
+	for name, provider := range providers {
+		rate, burst := cfg.PaymentServerRateLimit, cfg.PaymentServerRateBurst
+		if provider.RateLimit != nil {
+			rate = *provider.RateLimit
+		}
+		if provider.RateBurst != nil {
+			burst = *provider.RateBurst
+		}
+		limiter := newPaymentServerLimiter(cfg, redisClient, name, rate, burst, logger)
+		client, err := pkgPaymentServer.NewClient(provider.ClientConfig(name, defaults), limiter, logger)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("❌ Invalid payment providers")
+		}
+		registry.Register(client)
+		logger.Info().Msgf("🔗 Payment provider registered: %s (%s)", name, provider.BaseURL)
+	}
+
+	tags := map[string]string{}
+	if cfg.PaymentProviderTags != "" {
+		if err := json.Unmarshal([]byte(cfg.PaymentProviderTags), &tags); err != nil {
+			logger.Fatal().Err(err).Msg("❌ Invalid payment provider tags")
+		}
+	}
+	for tag, provider := range tags {
+		if err := registry.RouteTag(tag, provider); err != nil {
+			logger.Fatal().Err(err).Msg("❌ Invalid payment provider tags")
+		}
+	}
+	return registry
+}
+
+// loadStatusMappings membaca mapping status per provider; provider tanpa mapping memakai mapping default.
+func loadStatusMappings(cfg *config.AppConfig, paymentProviders *payment_provider.Registry, logger zerolog.Logger) entity.StatusMappings {
+	mappings, err := entity.ParseStatusMappings(cfg.PaymentStatusMappings)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("❌ Invalid payment status mappings")
+	}
+	for provider := range mappings {
+		if _, err := paymentProviders.Get(provider); err != nil {
+			logger.Warn().Err(err).Msgf("‼️ Status mapping defined for unregistered provider %s", provider)
+		}
+	}
+	for _, provider := range paymentProviders.Providers() {
+		if _, ok := mappings[provider.Name()]; !ok {
+			logger.Info().Msgf("🗺️ Provider %s uses the default status mapping", provider.Name())
+		}
+	}
+	return entity.StatusMappings{Default: entity.DefaultStatusMapping(), Providers: mappings}
+}
+
+// newReadinessChecker mendaftarkan semua dependency yang harus up supaya instance dianggap ready.
+func newReadinessChecker(cfg *config.AppConfig, db *sql.DB, redisClient *redis.Client, kafkaProducer *pkgKafka.KafkaProducerClient, paymentProviders *payment_provider.Registry, logger zerolog.Logger) *healthcheck.Checker {
+	checker := healthcheck.NewChecker(cfg.ReadinessInterval, cfg.ReadinessTimeout, logger)
+	checker.Register("postgres", db.PingContext)
+	checker.Register("redis", func(ctx context.Context) error {
+		return redisClient.Ping(ctx).Err()
+	})
+	checker.Register("kafka", kafkaProducer.Ping)
+	for _, provider := range paymentProviders.Providers() {
+		checker.Register("payment_provider:"+provider.Name(), provider.Health)
+	}
+	return checker
+}
+
+func closeKafka(consumer *pkgKafka.KafkaConsumerClient, producer *pkgKafka.KafkaProducerClient, logger zerolog.Logger) {
+	if err := consumer.Close(); err != nil {
+		logger.Info().Msgf("⚠️ Failed to close Kafka reader: %v", err)
+	} else {
+		logger.Info().Msgf("🔒 Kafka reader closed.")
+	}
+	if err := producer.Close(); err != nil {
+		logger.Info().Msgf("⚠️ Failed to close Kafka writer: %v", err)
+	} else {
+		logger.Info().Msgf("🔒 Kafka writer closed.")
+	}
+}
+
+func closeRedis(redisClient *redis.Client, logger zerolog.Logger) {
+	if err := redisClient.Close(); err != nil {
+		logger.Info().Msgf("⚠️ Failed to close Redis connection: %v", err)
+	} else {
+		logger.Info().Msgf("🔒 Redis connection closed.")
+	}
+}
+
 func closePostgres(db *sql.DB, logger zerolog.Logger) {
 	if err := db.Close(); err != nil {
 		logger.Info().Msgf("⚠️ Failed to close PostgreSQL connection: %v", err)
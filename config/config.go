@@ -1,9 +1,12 @@
 package config
 
 import (
+	"fmt"
 	"github.com/joho/godotenv"
 	"log"
 	"os"
+	"strconv"
+	"time"
 )
 
 type AppConfig struct {
@@ -23,9 +26,60 @@ type AppConfig struct {
 	RedisPassword            string
 	PaymentServerBaseURL     string
 	PaymentServerAPIKey      string
+	PaymentServerTimeout     time.Duration
+	PaymentServerConnTimeout time.Duration
+	PaymentServerReadTimeout time.Duration
+	PaymentServerMaxBody     int64
+	PaymentServerAuth        string
+	PaymentServerTLSCert     string
+	PaymentServerTLSKey      string
+	PaymentServerTLSCA       string
+	OAuth2TokenURL           string
+	OAuth2ClientID           string
+	OAuth2ClientSecret       string
+	OAuth2Scopes             string
+	OAuth2RefreshBefore      time.Duration
+	PaymentServerRateLimit   float64
+	PaymentServerRateBurst   int
+	PaymentServerRateBackend string
+	PaymentProviders         string
+	PaymentProviderTags      string
+	PaymentStatusMappings    string
+	BreakerFailureThreshold  int
+	BreakerOpenTimeout       time.Duration
+	BreakerHalfOpenRequests  int
 	KafkaHost                string
 	KafkaPort                string
 	KafkaTopicPaymentSuccess string
+	KafkaTopicPaymentTimeout string
+	PollingInitialDelay      time.Duration
+	PollingMultiplier        float64
+	PollingMaxDelay          time.Duration
+	PollingJitter            float64
+	PollingBoostResetDelay   time.Duration
+	PollingMaxAttempts       int           // wajib positif; 0 (tanpa batas) hanya lewat tag policy / override
+	PollingTimeout           time.Duration // wajib positif; 0 (tanpa batas) hanya lewat tag policy / override
+	PollingTagPolicies       string
+	PollingWorkers           int
+	PollingQueueSize         int
+	InstanceID               string
+	PollingLeaseTTL          time.Duration
+	PollingLeaseHeartbeat    time.Duration
+	ShutdownTimeout          time.Duration
+	ReadinessInterval        time.Duration
+	ReadinessTimeout         time.Duration
+	CheckLogRetentionDays    int
+	CheckLogArchiveDays      int
+	CheckLogPartitionsAhead  int
+	CheckLogRetentionMode    string
+	CheckLogRetentionEvery   time.Duration
+	CheckLogBufferSize       int
+	CheckLogBatchSize        int
+	CheckLogFlushInterval    time.Duration
+	CheckLogOverflow         string
+	RedactHeaders            string
+	RedactJSONPaths          string
+	RedactPatterns           string
 }
 
 func LoadConfig() *AppConfig {
@@ -51,15 +105,135 @@ func LoadConfig() *AppConfig {
 		RedisPassword:            getEnv("REDIS_PASSWORD", "not_set"),
 		PaymentServerBaseURL:     getEnv("PAYMENT_SERVER_BASE_URL", "not_set"),
 		PaymentServerAPIKey:      getEnv("PAYMENT_SERVER_API_KEY", "not_set"),
+		PaymentServerTimeout:     getEnvDuration("PAYMENT_SERVER_TIMEOUT", 15*time.Second),
+		PaymentServerConnTimeout: getEnvDuration("PAYMENT_SERVER_CONNECT_TIMEOUT", 3*time.Second),
+		PaymentServerReadTimeout: getEnvDuration("PAYMENT_SERVER_READ_TIMEOUT", 10*time.Second),
+		PaymentServerMaxBody:     int64(getEnvInt("PAYMENT_SERVER_MAX_BODY_BYTES", 1<<20)),
+		PaymentServerAuth:        getEnv("PAYMENT_SERVER_AUTH", "bearer"),
+		PaymentServerTLSCert:     getEnv("PAYMENT_SERVER_TLS_CERT_FILE", ""),
+		PaymentServerTLSKey:      getEnv("PAYMENT_SERVER_TLS_KEY_FILE", ""),
+		PaymentServerTLSCA:       getEnv("PAYMENT_SERVER_TLS_CA_FILE", ""),
+		OAuth2TokenURL:           getEnv("PAYMENT_SERVER_OAUTH2_TOKEN_URL", ""),
+		OAuth2ClientID:           getEnv("PAYMENT_SERVER_OAUTH2_CLIENT_ID", ""),
+		OAuth2ClientSecret:       getEnv("PAYMENT_SERVER_OAUTH2_CLIENT_SECRET", ""),
+		OAuth2Scopes:             getEnv("PAYMENT_SERVER_OAUTH2_SCOPES", ""),
+		OAuth2RefreshBefore:      getEnvDuration("PAYMENT_SERVER_OAUTH2_REFRESH_BEFORE", 60*time.Second),
+		PaymentServerRateLimit:   getEnvFloat("PAYMENT_SERVER_RATE_LIMIT", 20),
+		PaymentServerRateBurst:   getEnvInt("PAYMENT_SERVER_RATE_BURST", 20),
+		PaymentServerRateBackend: getEnv("PAYMENT_SERVER_RATE_BACKEND", "memory"),
+		PaymentProviders:         getEnv("PAYMENT_PROVIDERS", ""),
+		PaymentProviderTags:      getEnv("PAYMENT_PROVIDER_TAGS", ""),
+		PaymentStatusMappings:    getEnv("PAYMENT_STATUS_MAPPINGS", ""),
+		BreakerFailureThreshold:  getEnvInt("PAYMENT_SERVER_BREAKER_FAILURE_THRESHOLD", 5),
+		BreakerOpenTimeout:       getEnvDuration("PAYMENT_SERVER_BREAKER_OPEN_TIMEOUT", 30*time.Second),
+		BreakerHalfOpenRequests:  getEnvInt("PAYMENT_SERVER_BREAKER_HALF_OPEN_REQUESTS", 1),
 		KafkaHost:                getEnv("KAFKA_HOST", "not_set"),
 		KafkaPort:                getEnv("KAFKA_PORT", "not_set"),
 		KafkaTopicPaymentSuccess: getEnv("KAFKA_TOPIC_PAYMENT_SUCCESS", "not_set"),
+		KafkaTopicPaymentTimeout: getEnv("KAFKA_TOPIC_PAYMENT_TIMEOUT", "not_set"),
+		PollingInitialDelay:      getEnvDuration("POLLING_INITIAL_DELAY", 10*time.Second),
+		PollingMultiplier:        getEnvFloat("POLLING_MULTIPLIER", 2),
+		PollingMaxDelay:          getEnvDuration("POLLING_MAX_DELAY", 80*time.Second),
+		PollingJitter:            getEnvFloat("POLLING_JITTER", 0),
+		PollingBoostResetDelay:   getEnvDuration("POLLING_BOOST_RESET_DELAY", 10*time.Second),
+		PollingMaxAttempts:       getEnvInt("POLLING_MAX_ATTEMPTS", 500),
+		PollingTimeout:           getEnvDuration("POLLING_TIMEOUT", 24*time.Hour),
+		PollingTagPolicies:       getEnv("POLLING_TAG_POLICIES", ""),
+		PollingWorkers:           getEnvInt("POLLING_WORKERS", 16),
+		PollingQueueSize:         getEnvInt("POLLING_QUEUE_SIZE", 50000),
+		InstanceID:               getEnv("INSTANCE_ID", defaultInstanceID()),
+		PollingLeaseTTL:          getEnvDuration("POLLING_LEASE_TTL", 30*time.Second),
+		PollingLeaseHeartbeat:    getEnvDuration("POLLING_LEASE_HEARTBEAT", 10*time.Second),
+		ShutdownTimeout:          getEnvDuration("SHUTDOWN_TIMEOUT", 30*time.Second),
+		ReadinessInterval:        getEnvDuration("READINESS_CHECK_INTERVAL", 10*time.Second),
+		ReadinessTimeout:         getEnvDuration("READINESS_CHECK_TIMEOUT", 3*time.Second),
+		CheckLogRetentionDays:    getEnvInt("CHECK_LOG_RETENTION_DAYS", 30),
+		CheckLogArchiveDays:      getEnvInt("CHECK_LOG_FINALIZED_RETENTION_DAYS", 365),
+		CheckLogPartitionsAhead:  getEnvInt("CHECK_LOG_PARTITIONS_AHEAD", 2),
+		CheckLogRetentionMode:    getEnv("CHECK_LOG_RETENTION_MODE", "drop"),
+		CheckLogRetentionEvery:   getEnvDuration("CHECK_LOG_RETENTION_INTERVAL", time.Hour),
+		CheckLogBufferSize:       getEnvInt("CHECK_LOG_BUFFER_SIZE", 10000),
+		CheckLogBatchSize:        getEnvInt("CHECK_LOG_BATCH_SIZE", 500),
+		CheckLogFlushInterval:    getEnvDuration("CHECK_LOG_FLUSH_INTERVAL", time.Second),
+		CheckLogOverflow:         getEnv("CHECK_LOG_OVERFLOW_POLICY", "drop"),
+		RedactHeaders:            getEnv("REDACT_HEADERS", ""),
+		RedactJSONPaths:          getEnv("REDACT_JSON_PATHS", ""),
+		RedactPatterns:           getEnv("REDACT_PATTERNS", ""),
 	}
 }
 
+// Validate menolak nilai konfigurasi yang membuat aplikasi salah jalan diam-diam.
+func (c *AppConfig) Validate() error {
+	if c.PaymentServerMaxBody <= 0 {
+		return fmt.Errorf("PAYMENT_SERVER_MAX_BODY_BYTES must be positive, got %d", c.PaymentServerMaxBody)
+	}
+	if c.PollingMaxAttempts <= 0 {
+		return fmt.Errorf("POLLING_MAX_ATTEMPTS must be positive, got %d", c.PollingMaxAttempts)
+	}
+	if c.PollingTimeout <= 0 {
+		return fmt.Errorf("POLLING_TIMEOUT must be positive, got %s", c.PollingTimeout)
+	}
+	if c.PollingLeaseHeartbeat <= 0 {
+		return fmt.Errorf("POLLING_LEASE_HEARTBEAT must be positive, got %s", c.PollingLeaseHeartbeat)
+	}
+	// Lease harus hidup lebih lama dari jeda heartbeat, kalau tidak lease expired sebelum sempat diperpanjang
+	if c.PollingLeaseTTL <= c.PollingLeaseHeartbeat {
+		return fmt.Errorf("POLLING_LEASE_TTL (%s) must be greater than POLLING_LEASE_HEARTBEAT (%s)", c.PollingLeaseTTL, c.PollingLeaseHeartbeat)
+	}
+	return nil
+}
+
 func getEnv(key, defaultVal string) string {
 	if val, exists := os.LookupEnv(key); exists {
 		return val
 	}
 	return defaultVal
 }
+
+// defaultInstanceID: hostname (nama pod) + pid supaya unik antar replica
+func defaultInstanceID() string {
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "instance"
+	}
+	return fmt.Sprintf("%s-%d", hostname, os.Getpid())
+}
+
+func getEnvDuration(key string, defaultVal time.Duration) time.Duration {
+	val, exists := os.LookupEnv(key)
+	if !exists || val == "" {
+		return defaultVal
+	}
+	d, err := time.ParseDuration(val)
+	if err != nil {
+		log.Printf("Invalid duration for %s: %q, using default %s", key, val, defaultVal)
+		return defaultVal
+	}
+	return d
+}
+
+func getEnvInt(key string, defaultVal int) int {
+	val, exists := os.LookupEnv(key)
+	if !exists || val == "" {
+		return defaultVal
+	}
+	i, err := strconv.Atoi(val)
+	if err != nil {
+		log.Printf("Invalid number for %s: %q, using default %d", key, val, defaultVal)
+		return defaultVal
+	}
+	return i
+}
+
+func getEnvFloat(key string, defaultVal float64) float64 {
+	val, exists := os.LookupEnv(key)
+	if !exists || val == "" {
+		return defaultVal
+	}
+	f, err := strconv.ParseFloat(val, 64)
+	if err != nil {
+		log.Printf("Invalid number for %s: %q, using default %v", key, val, defaultVal)
+		return defaultVal
+	}
+	return f
+}
@@ -0,0 +1,15 @@
+package check_log
+
+import (
+	"beta-payment-api-client/internal/usecase"
+	"github.com/rs/zerolog"
+)
+
+type CheckLogHandler struct {
+	CheckLogReplayUC usecase.CheckLogReplayUseCase
+	Logger           zerolog.Logger
+}
+
+func NewCheckLogHandler(checkLogReplay usecase.CheckLogReplayUseCase, logger zerolog.Logger) *CheckLogHandler {
+	return &CheckLogHandler{CheckLogReplayUC: checkLogReplay, Logger: logger}
+}
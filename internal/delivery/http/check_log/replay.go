@@ -0,0 +1,55 @@
+package check_log
+
+import (
+	"beta-payment-api-client/internal/delivery/http/router"
+	"beta-payment-api-client/internal/delivery/response"
+	"beta-payment-api-client/internal/usecase"
+	"database/sql"
+	"errors"
+	"github.com/google/uuid"
+	"net/http"
+)
+
+// Replay godoc
+// @Summary      Replay a logged payment server request
+// @Description  Rebuild the request stored in a payment_record_check_logs row, send it again with fresh credentials
+// @Description  and return the old vs new response status and body diff. Polling state and check logs are not touched.
+// @Tags         check_logs
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "UUID of the check log"
+// @Success      200  {object}  response.APIResponse
+// @Failure      401  {object}  response.APIResponse  "Unauthorized"
+// @Failure      404  {object}  response.APIResponse  "Check log or payment record not found"
+// @Failure      422  {object}  response.APIResponse  "Invalid UUID, unknown provider or URL outside the provider"
+// @Failure      500  {object}  response.APIResponse  "Internal server error"
+// @Router       /api/v1/payment-records/check/logs/{id}/replay [post]
+func (c *CheckLogHandler) Replay(w http.ResponseWriter, r *http.Request) {
+	c.Logger.Info().Msg("📥 Incoming Replay request")
+
+	id, err := uuid.Parse(router.GetParam(r, "id"))
+	if err != nil {
+		c.Logger.Error().Err(err).Msg("❌ Invalid UUID parameter")
+		response.Failed(w, 422, "checkLogs", "replay", "Invalid UUID")
+		return
+	}
+
+	replay, err := c.CheckLogReplayUC.Replay(r.Context(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			c.Logger.Warn().Err(err).Msg("‼️ Check log not found")
+			response.Failed(w, 404, "checkLogs", "replay", "Check Log Not Found")
+		case errors.Is(err, usecase.ErrUnknownProvider), errors.Is(err, usecase.ErrForeignReplayURL):
+			c.Logger.Error().Err(err).Msg("❌ Check log cannot be replayed")
+			response.Failed(w, 422, "checkLogs", "replay", "Check Log Cannot Be Replayed")
+		default:
+			c.Logger.Error().Err(err).Msg("❌ Failed to replay check log")
+			response.Failed(w, 500, "checkLogs", "replay", "Error Replay Check Log")
+		}
+		return
+	}
+
+	c.Logger.Info().Str("check_log_id", id.String()).Msg("✅ Successfully replayed check log")
+	response.Success(w, 200, "checkLogs", "replay", "Success Replay Check Log", replay)
+}
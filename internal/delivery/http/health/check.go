@@ -2,16 +2,27 @@ package health
 
 import (
 	"beta-payment-api-client/internal/delivery/response"
+	"beta-payment-api-client/internal/pkg/circuitbreaker"
 	"net/http"
 )
 
 // Health godoc
 // @Summary      Health Check
-// @Description  Health check for service
+// @Description  Health check for service, including the circuit breaker state of each payment provider
 // @Tags         health
 // @Success      200  {object}  response.APIResponse
 // @Router       /healthz [get]
 func (h *HealthHandler) Check(w http.ResponseWriter, r *http.Request) {
 	h.Logger.Info().Msg("📥 Incoming health check request")
-	response.Success(w, 200, "health", "healthCheck", "Success Health Check", nil)
+
+	circuits := map[string]circuitbreaker.Snapshot{}
+	for _, provider := range h.PaymentProviders.Providers() {
+		if withBreaker, ok := provider.(breakerProvider); ok {
+			circuits[provider.Name()] = withBreaker.Breaker().Snapshot()
+		}
+	}
+	data := map[string]any{
+		"payment_provider_circuits": circuits,
+	}
+	response.Success(w, 200, "health", "healthCheck", "Success Health Check", data)
 }
@@ -1,13 +1,23 @@
 package health
 
 import (
+	"beta-payment-api-client/internal/pkg/circuitbreaker"
+	"beta-payment-api-client/internal/pkg/healthcheck"
+	"beta-payment-api-client/internal/pkg/payment_provider"
 	"github.com/rs/zerolog"
 )
 
 type HealthHandler struct {
-	Logger zerolog.Logger
+	PaymentProviders *payment_provider.Registry
+	Readiness        *healthcheck.Checker
+	Logger           zerolog.Logger
 }
 
-func NewHealthHandler(logger zerolog.Logger) *HealthHandler {
-	return &HealthHandler{Logger: logger}
+func NewHealthHandler(paymentProviders *payment_provider.Registry, readiness *healthcheck.Checker, logger zerolog.Logger) *HealthHandler {
+	return &HealthHandler{PaymentProviders: paymentProviders, Readiness: readiness, Logger: logger}
+}
+
+// breakerProvider provider yang punya circuit breaker (mis. PaymentServerClient).
+type breakerProvider interface {
+	Breaker() *circuitbreaker.Breaker
 }
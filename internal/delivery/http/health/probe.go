@@ -0,0 +1,36 @@
+package health
+
+import (
+	"beta-payment-api-client/internal/delivery/response"
+	"net/http"
+)
+
+// Live godoc
+// @Summary      Liveness probe
+// @Description  Returns 200 as long as the process is running; does not check dependencies
+// @Tags         health
+// @Success      200  {object}  response.APIResponse
+// @Router       /livez [get]
+func (h *HealthHandler) Live(w http.ResponseWriter, r *http.Request) {
+	response.Success(w, 200, "health", "liveness", "Alive", nil)
+}
+
+// Ready godoc
+// @Summary      Readiness probe
+// @Description  Latest result of the periodic Postgres, Redis, Kafka and payment provider checks, with per-dependency status and latency
+// @Tags         health
+// @Success      200  {object}  response.APIResponse
+// @Failure      503  {object}  response.APIResponse  "One or more dependencies are down"
+// @Router       /readyz [get]
+func (h *HealthHandler) Ready(w http.ResponseWriter, r *http.Request) {
+	ready, dependencies := h.Readiness.Ready()
+	data := map[string]any{
+		"ready":        ready,
+		"dependencies": dependencies,
+	}
+	if !ready {
+		response.JSON(w, 503, "health", "readiness", "Not Ready", data, false)
+		return
+	}
+	response.Success(w, 200, "health", "readiness", "Ready", data)
+}
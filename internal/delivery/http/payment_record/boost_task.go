@@ -0,0 +1,67 @@
+package payment_record
+
+import (
+	"beta-payment-api-client/internal/delivery/http/router"
+	"beta-payment-api-client/internal/delivery/response"
+	"beta-payment-api-client/internal/usecase"
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/google/uuid"
+	"net/http"
+	"time"
+)
+
+// BoostTask godoc
+// @Summary      Boost polling task
+// @Description  Trigger an immediate check for a single payment record (starting polling if needed) and return its result
+// @Tags         payment_records
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "UUID of the payment record"
+// @Success      200  {object}  response.APIResponse
+// @Failure      401  {object}  response.APIResponse  "Unauthorized"
+// @Failure      404  {object}  response.APIResponse  "Payment record not found"
+// @Failure      409  {object}  response.APIResponse  "Payment finalized or task owned by another instance"
+// @Failure      422  {object}  response.APIResponse  "Invalid UUID"
+// @Failure      504  {object}  response.APIResponse  "Check did not finish in time"
+// @Failure      500  {object}  response.APIResponse  "Internal server error"
+// @Router       /api/v1/payment-records/check/tasks/{id}/boost [post]
+func (p *PaymentRecordHandler) BoostTask(w http.ResponseWriter, r *http.Request) {
+	p.Logger.Info().Msg("📥 Incoming BoostTask request")
+
+	id, err := uuid.Parse(router.GetParam(r, "id"))
+	if err != nil {
+		p.Logger.Error().Err(err).Msg("❌ Invalid UUID parameter")
+		response.Failed(w, 422, "paymentRecords", "boostTask", "Invalid UUID")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	task, err := p.PaymentRecordUC.BoostTask(ctx, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			p.Logger.Warn().Err(err).Msg("‼️ Payment record not found")
+			response.Failed(w, 404, "paymentRecords", "boostTask", "Payment Record Not Found")
+		case errors.Is(err, usecase.ErrPaymentFinalized):
+			p.Logger.Warn().Err(err).Msg("‼️ Payment already finalized")
+			response.Failed(w, 409, "paymentRecords", "boostTask", "Payment Already Finalized")
+		case errors.Is(err, usecase.ErrPollingTaskNotOwned):
+			p.Logger.Warn().Err(err).Msg("‼️ Polling task owned by another instance")
+			response.Failed(w, 409, "paymentRecords", "boostTask", "Polling Task Owned By Another Instance")
+		case errors.Is(err, context.DeadlineExceeded):
+			p.Logger.Warn().Err(err).Msg("‼️ Boosted check did not finish in time")
+			response.Failed(w, 504, "paymentRecords", "boostTask", "Boosted Check Timed Out")
+		default:
+			p.Logger.Error().Err(err).Msg("❌ Failed to boost polling task")
+			response.Failed(w, 500, "paymentRecords", "boostTask", "Error Boost Polling Task")
+		}
+		return
+	}
+
+	p.Logger.Info().Str("payment_id", id.String()).Msg("✅ Successfully boosted polling task")
+	response.Success(w, 200, "paymentRecords", "boostTask", "Success Boost Polling Task", task)
+}
@@ -0,0 +1,85 @@
+package payment_record
+
+import (
+	"beta-payment-api-client/internal/delivery/http/router"
+	"beta-payment-api-client/internal/delivery/response"
+	"beta-payment-api-client/internal/entity"
+	"beta-payment-api-client/internal/usecase"
+	"errors"
+	"github.com/google/uuid"
+	"net/http"
+)
+
+// CancelTask godoc
+// @Summary      Cancel polling task
+// @Description  Stop polling a payment record and mark it as CANCELLED
+// @Tags         payment_records
+// @Security     BearerAuth
+// @Param        id   path      string  true  "UUID of the payment record"
+// @Success      200  {object}  response.APIResponse
+// @Failure      401  {object}  response.APIResponse  "Unauthorized"
+// @Failure      404  {object}  response.APIResponse  "Task not found"
+// @Failure      409  {object}  response.APIResponse  "Payment already finalized"
+// @Failure      422  {object}  response.APIResponse  "Invalid UUID"
+// @Failure      500  {object}  response.APIResponse  "Internal server error"
+// @Router       /api/v1/payment-records/check/tasks/{id} [delete]
+func (p *PaymentRecordHandler) CancelTask(w http.ResponseWriter, r *http.Request) {
+	p.Logger.Info().Msg("📥 Incoming CancelTask request")
+
+	id, err := uuid.Parse(router.GetParam(r, "id"))
+	if err != nil {
+		p.Logger.Error().Err(err).Msg("❌ Invalid UUID parameter")
+		response.Failed(w, 422, "paymentRecords", "cancelTask", "Invalid UUID")
+		return
+	}
+
+	if err := p.PaymentRecordUC.CancelTask(r.Context(), id); err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrPollingTaskNotFound):
+			p.Logger.Warn().Err(err).Msg("‼️ Polling task not found")
+			response.Failed(w, 404, "paymentRecords", "cancelTask", "Polling Task Not Found")
+		case errors.Is(err, entity.ErrInvalidStatusTransition):
+			p.Logger.Warn().Err(err).Msg("‼️ Payment already finalized")
+			response.Failed(w, 409, "paymentRecords", "cancelTask", "Payment Already Finalized")
+		default:
+			p.Logger.Error().Err(err).Msg("❌ Failed to cancel polling task")
+			response.Failed(w, 500, "paymentRecords", "cancelTask", "Error Cancel Polling Task")
+		}
+		return
+	}
+
+	p.Logger.Info().Str("payment_id", id.String()).Msg("✅ Successfully cancelled polling task")
+	response.Success(w, 200, "paymentRecords", "cancelTask", "Success Cancel Polling Task", []uuid.UUID{id})
+}
+
+// CancelTasksByTag godoc
+// @Summary      Cancel polling tasks by tag
+// @Description  Stop polling every payment record with the given tag and mark them as CANCELLED
+// @Tags         payment_records
+// @Security     BearerAuth
+// @Param        tag  query     string  true  "Tag of the payment records"
+// @Success      200  {object}  response.APIResponse
+// @Failure      401  {object}  response.APIResponse  "Unauthorized"
+// @Failure      422  {object}  response.APIResponse  "Tag is required"
+// @Failure      500  {object}  response.APIResponse  "Internal server error"
+// @Router       /api/v1/payment-records/check/tasks [delete]
+func (p *PaymentRecordHandler) CancelTasksByTag(w http.ResponseWriter, r *http.Request) {
+	p.Logger.Info().Msg("📥 Incoming CancelTasksByTag request")
+
+	tag := r.URL.Query().Get("tag")
+	if tag == "" {
+		p.Logger.Error().Msg("❌ Tag query parameter is required")
+		response.Failed(w, 422, "paymentRecords", "cancelTasksByTag", "Tag Is Required")
+		return
+	}
+
+	cancelled, err := p.PaymentRecordUC.CancelTasksByTag(r.Context(), tag)
+	if err != nil {
+		p.Logger.Error().Err(err).Msg("❌ Failed to cancel polling tasks")
+		response.Failed(w, 500, "paymentRecords", "cancelTasksByTag", "Error Cancel Polling Tasks")
+		return
+	}
+
+	p.Logger.Info().Int("count", len(cancelled)).Msg("✅ Successfully cancelled polling tasks")
+	response.Success(w, 200, "paymentRecords", "cancelTasksByTag", "Success Cancel Polling Tasks", cancelled)
+}
@@ -4,6 +4,7 @@ import (
 	"beta-payment-api-client/internal/delivery/request"
 	"beta-payment-api-client/internal/delivery/response"
 	"beta-payment-api-client/internal/entity"
+	"beta-payment-api-client/internal/usecase"
 	"beta-payment-api-client/internal/valueobject"
 	"context"
 	"database/sql"
@@ -53,24 +54,38 @@ func (p *PaymentRecordHandler) CheckByID(w http.ResponseWriter, r *http.Request)
 	paymentRecord, err := p.PaymentRecordUC.GetByID(r.Context(), id)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
+			// Policy & provider dicek dulu: request invalid tidak boleh membuat record yang tidak pernah di-poll
+			if err := p.PaymentRecordUC.ValidatePolling(req.Tag, req.Provider, req.PollingPolicy); err != nil {
+				p.failStartPolling(w, err)
+				return
+			}
 
 			zero := valueobject.BigFloat{Float: big.NewFloat(0)}
 
 			paymentRecordCreate := entity.PaymentRecord{
 				ID:          id,
-				Tag:         "",
+				Tag:         req.Tag,
+				Provider:    req.Provider,
 				Description: "",
 				Amount:      zero,
-				Status:      "",
+				Status:      entity.PaymentStatusPending,
 			}
 
 			newPayment, err := p.PaymentRecordUC.Create(r.Context(), paymentRecordCreate)
+			if errors.Is(err, usecase.ErrUnknownProvider) {
+				p.Logger.Error().Err(err).Msg("❌ Unknown payment provider")
+				response.Failed(w, 422, "paymentRecords", "checkPaymentRecordByID", "Unknown Payment Provider")
+				return
+			}
 			if err != nil {
 				p.Logger.Error().Err(err).Msg("❌ Failed to store payment, general")
 				response.Failed(w, 500, "paymentRecords", "checkPaymentRecordByID", "Error Create Payment")
 				return
 			}
-			_ = p.PaymentRecordUC.StartPolling(context.Background(), id)
+			if err := p.PaymentRecordUC.StartPolling(context.Background(), id, newPayment.Tag, newPayment.Provider, req.PollingPolicy); err != nil {
+				p.failStartPolling(w, err)
+				return
+			}
 			p.Logger.Info().Str("data", fmt.Sprint(newPayment)).Msg("✅ Successfully stored payment")
 			response.Success(w, 200, "paymentRecords", "checkPaymentRecordByID", "Success Check Payment Record by ID", newPayment)
 			return
@@ -79,7 +94,46 @@ func (p *PaymentRecordHandler) CheckByID(w http.ResponseWriter, r *http.Request)
 		response.Failed(w, 500, "paymentRecords", "checkPaymentRecordByID", "Error Get Payment by ID")
 		return
 	}
-	_ = p.PaymentRecordUC.StartPolling(context.Background(), id)
+	// Status sudah final → kembalikan apa adanya, tidak perlu polling lagi
+	if paymentRecord.Status.IsFinal() {
+		p.Logger.Info().Str("data", fmt.Sprint(paymentRecord.ID)).Msgf("✅ Payment already finalized: %s", paymentRecord.Status)
+		response.Success(w, 200, "paymentRecords", "checkPaymentRecordByID", "Success Get Payment by ID", paymentRecord)
+		return
+	}
+
+	if err := p.PaymentRecordUC.ValidatePolling(paymentRecord.Tag, paymentRecord.Provider, req.PollingPolicy); err != nil {
+		p.failStartPolling(w, err)
+		return
+	}
+
+	// TIMED_OUT di-check ulang → kembali PENDING lalu polling dari awal
+	if paymentRecord.Status == entity.PaymentStatusTimedOut {
+		if err := p.PaymentRecordUC.UpdateStatus(r.Context(), id, entity.PaymentStatusPending); err != nil {
+			p.Logger.Error().Err(err).Msg("❌ Failed to reset timed out payment")
+			response.Failed(w, 500, "paymentRecords", "checkPaymentRecordByID", "Error Update Payment Status")
+			return
+		}
+		paymentRecord.Status = entity.PaymentStatusPending
+	}
+
+	if err := p.PaymentRecordUC.StartPolling(context.Background(), id, paymentRecord.Tag, paymentRecord.Provider, req.PollingPolicy); err != nil {
+		p.failStartPolling(w, err)
+		return
+	}
 	p.Logger.Info().Str("data", fmt.Sprint(paymentRecord.ID)).Msg("✅ Successfully get payment by id")
 	response.Success(w, 200, "paymentRecords", "checkPaymentRecordByID", "Success Get Payment by ID", paymentRecord)
 }
+
+func (p *PaymentRecordHandler) failStartPolling(w http.ResponseWriter, err error) {
+	p.Logger.Error().Err(err).Msg("❌ Failed to start polling")
+	switch {
+	case errors.Is(err, usecase.ErrInvalidPollingPolicy):
+		response.Failed(w, 422, "paymentRecords", "checkPaymentRecordByID", "Invalid Polling Policy")
+	case errors.Is(err, usecase.ErrUnknownProvider):
+		response.Failed(w, 422, "paymentRecords", "checkPaymentRecordByID", "Unknown Payment Provider")
+	case errors.Is(err, usecase.ErrPollingQueueFull):
+		response.Failed(w, 503, "paymentRecords", "checkPaymentRecordByID", "Polling Queue Full")
+	default:
+		response.Failed(w, 500, "paymentRecords", "checkPaymentRecordByID", "Error Start Polling")
+	}
+}
@@ -1,42 +1,59 @@
 package payment_record
 
 import (
+	"beta-payment-api-client/internal/delivery/http/router"
+	"beta-payment-api-client/internal/delivery/request"
 	"beta-payment-api-client/internal/delivery/response"
+	"github.com/google/uuid"
 	"net/http"
 )
 
-// GetAllBooks godoc
-// @Summary      Get list of books
-// @Description  List all books with filter, search, pagination
-// @Tags         books
-// @Accept       json
+// CheckHistoryByID godoc
+// @Summary      List check histories of a payment record
+// @Description  List every attempt to check a payment against the payment server (status code, delay, headers, decoded body), newest first
+// @Description  Use page/per_page for small histories, or follow meta.next_cursor for payments with thousands of attempts
+// @Tags         payment_records
 // @Produce      json
-//
-// --- Search Query ---
-// @Param        search_field      query    string   false  "Search field (e.g., title)"
-// @Param        search_value      query    string   false  "Search value (e.g., golang)"
-//
-// --- Filter Search Query ---
-// @Param filter_field query []string false "Filter field" collectionFormat(multi) explode(true)
-// @Param filter_value query []string false "Filter value" collectionFormat(multi) explode(true)
-//
-// --- Range Query ---
-// @Param range_field query []string false "Range field" collectionFormat(multi) explode(true)
-// @Param from        query []string false "Range lower bound" collectionFormat(multi) explode(true)
-// @Param to          query []string false "Range upper bound" collectionFormat(multi) explode(true)
-//
-// --- Pagination & Sort ---
-// @Param        sort_field        query    string   false  "Sort field"
-// @Param        sort_direction    query    string   false  "Sort direction ASC/DESC"
-// @Param        page              query    int      false  "Page number"
-// @Param        per_page          query    int      false  "Limit per page"
-//
 // @Security     BearerAuth
-//
-// @Success      200     {object}  response.APIResponse
-// @Failure      500     {object}  response.APIResponse
-// @Router       /books [get]
+// @Param        id              path     string  true   "UUID of the payment record"
+// @Param        from            query    string  false  "Lower bound of occurred_at, inclusive (RFC3339)"
+// @Param        to              query    string  false  "Upper bound of occurred_at, exclusive (RFC3339)"
+// @Param        sort_direction  query    string  false  "Sort direction by occurred_at ASC/DESC (default DESC)"
+// @Param        cursor          query    string  false  "Cursor from meta.next_cursor; page is ignored when set"
+// @Param        page            query    int     false  "Page number"
+// @Param        per_page        query    int     false  "Limit per page (max 100)"
+// @Success      200  {object}  response.APIResponseWithMeta
+// @Failure      401  {object}  response.APIResponse  "Unauthorized"
+// @Failure      422  {object}  response.APIResponse  "Invalid UUID or query parameter"
+// @Failure      500  {object}  response.APIResponse  "Internal server error"
+// @Router       /api/v1/payment-records/check/histories/{id} [get]
 func (p *PaymentRecordHandler) CheckHistoryByID(w http.ResponseWriter, r *http.Request) {
-	p.Logger.Info().Msg("📥 Incoming GetByID request")
-	response.Success(w, 200, "paymentRecords", "checkPaymentRecordByID", "Success Check Payment Record by ID", nil)
+	p.Logger.Info().Msg("📥 Incoming CheckHistoryByID request")
+
+	id, err := uuid.Parse(router.GetParam(r, "id"))
+	if err != nil {
+		p.Logger.Error().Err(err).Msg("❌ Invalid UUID parameter")
+		response.Failed(w, 422, "paymentRecords", "checkHistoryByID", "Invalid UUID")
+		return
+	}
+
+	params := request.ParseCheckHistoryQueryParams(r)
+	filter, err := params.Filter(id)
+	if err != nil {
+		p.Logger.Error().Err(err).Msg("❌ Invalid query parameter")
+		response.Failed(w, 422, "paymentRecords", "checkHistoryByID", "Invalid Query Parameter")
+		return
+	}
+
+	histories, total, nextCursor, err := p.PaymentRecordUC.ListCheckHistories(r.Context(), filter)
+	if err != nil {
+		p.Logger.Error().Err(err).Msg("❌ Failed to fetch check histories")
+		response.Failed(w, 500, "paymentRecords", "checkHistoryByID", "Error Get Check Histories")
+		return
+	}
+	params.Total = total
+	params.NextCursor = nextCursor
+
+	p.Logger.Info().Str("payment_id", id.String()).Int("count", len(histories)).Msg("✅ Successfully fetched check histories")
+	response.SuccessWithMeta(w, 200, "paymentRecords", "checkHistoryByID", "Success Get Check Histories", params, histories)
 }
@@ -1,13 +1,46 @@
 package payment_record
 
 import (
+	"beta-payment-api-client/internal/delivery/request"
 	"beta-payment-api-client/internal/delivery/response"
 	"net/http"
 )
 
+// GetAllTask godoc
+// @Summary      List polling tasks
+// @Description  List polling tasks across all instances with backoff state, filter and pagination
+// @Tags         payment_records
+// @Produce      json
+// @Security     BearerAuth
+// @Param        status    query    string   false  "Last status returned by the payment server (e.g. PENDING)"
+// @Param        min_age   query    string   false  "Minimum task age as Go duration (e.g. 30m)"
+// @Param        max_age   query    string   false  "Maximum task age as Go duration (e.g. 2h)"
+// @Param        page      query    int      false  "Page number"
+// @Param        per_page  query    int      false  "Limit per page"
+// @Success      200  {object}  response.APIResponseWithMeta
+// @Failure      401  {object}  response.APIResponse  "Unauthorized"
+// @Failure      422  {object}  response.APIResponse  "Invalid query parameter"
+// @Failure      500  {object}  response.APIResponse  "Internal server error"
+// @Router       /api/v1/payment-records/check/tasks [get]
 func (p *PaymentRecordHandler) GetAllTask(w http.ResponseWriter, r *http.Request) {
 	p.Logger.Info().Msg("📥 Incoming GetAllTask request")
-	runningTasks := p.PaymentRecordUC.ListRunningTasks()
-	p.Logger.Info().Int("count", len(runningTasks)).Msg("✅ Successfully fetched payments")
-	response.Success(w, 200, "payment_records", "GetAllTask", "Success Get All Tasks", runningTasks)
+
+	params := request.ParsePollingTaskQueryParams(r)
+	filter, err := params.Filter()
+	if err != nil {
+		p.Logger.Error().Err(err).Msg("❌ Invalid query parameter")
+		response.Failed(w, 422, "payment_records", "GetAllTask", "Invalid Query Parameter")
+		return
+	}
+
+	tasks, total, err := p.PaymentRecordUC.ListTasks(r.Context(), filter)
+	if err != nil {
+		p.Logger.Error().Err(err).Msg("❌ Failed to fetch polling tasks")
+		response.Failed(w, 500, "payment_records", "GetAllTask", "Error Get All Tasks")
+		return
+	}
+	params.Total = total
+
+	p.Logger.Info().Int("count", len(tasks)).Msg("✅ Successfully fetched payments")
+	response.SuccessWithMeta(w, 200, "payment_records", "GetAllTask", "Success Get All Tasks", params, tasks)
 }
@@ -0,0 +1,49 @@
+package payment_record
+
+import (
+	"beta-payment-api-client/internal/delivery/http/router"
+	"beta-payment-api-client/internal/delivery/response"
+	"beta-payment-api-client/internal/usecase"
+	"errors"
+	"github.com/google/uuid"
+	"net/http"
+)
+
+// GetTaskByID godoc
+// @Summary      Get polling task by ID
+// @Description  Show backoff state, attempts, last result, next retry and owning instance of a polling task
+// @Tags         payment_records
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "UUID of the payment record"
+// @Success      200  {object}  response.APIResponse
+// @Failure      401  {object}  response.APIResponse  "Unauthorized"
+// @Failure      404  {object}  response.APIResponse  "Task not found"
+// @Failure      422  {object}  response.APIResponse  "Invalid UUID"
+// @Failure      500  {object}  response.APIResponse  "Internal server error"
+// @Router       /api/v1/payment-records/check/tasks/{id} [get]
+func (p *PaymentRecordHandler) GetTaskByID(w http.ResponseWriter, r *http.Request) {
+	p.Logger.Info().Msg("📥 Incoming GetTaskByID request")
+
+	id, err := uuid.Parse(router.GetParam(r, "id"))
+	if err != nil {
+		p.Logger.Error().Err(err).Msg("❌ Invalid UUID parameter")
+		response.Failed(w, 422, "paymentRecords", "getTaskByID", "Invalid UUID")
+		return
+	}
+
+	task, err := p.PaymentRecordUC.GetTask(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, usecase.ErrPollingTaskNotFound) {
+			p.Logger.Warn().Err(err).Msg("‼️ Polling task not found")
+			response.Failed(w, 404, "paymentRecords", "getTaskByID", "Polling Task Not Found")
+			return
+		}
+		p.Logger.Error().Err(err).Msg("❌ Failed to get polling task")
+		response.Failed(w, 500, "paymentRecords", "getTaskByID", "Error Get Polling Task")
+		return
+	}
+
+	p.Logger.Info().Str("payment_id", id.String()).Msg("✅ Successfully get polling task")
+	response.Success(w, 200, "paymentRecords", "getTaskByID", "Success Get Polling Task", task)
+}
@@ -0,0 +1,108 @@
+package payment_record
+
+import (
+	"beta-payment-api-client/internal/delivery/http/router"
+	"beta-payment-api-client/internal/delivery/response"
+	"beta-payment-api-client/internal/usecase"
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"net/http"
+)
+
+// PauseTask godoc
+// @Summary      Pause polling task
+// @Description  Suspend checks for a payment record while keeping its polling task registered
+// @Tags         payment_records
+// @Security     BearerAuth
+// @Param        id   path      string  true  "UUID of the payment record"
+// @Success      200  {object}  response.APIResponse
+// @Failure      401  {object}  response.APIResponse  "Unauthorized"
+// @Failure      404  {object}  response.APIResponse  "Task not found"
+// @Failure      422  {object}  response.APIResponse  "Invalid UUID"
+// @Failure      500  {object}  response.APIResponse  "Internal server error"
+// @Router       /api/v1/payment-records/check/tasks/{id}/pause [post]
+func (p *PaymentRecordHandler) PauseTask(w http.ResponseWriter, r *http.Request) {
+	p.Logger.Info().Msg("📥 Incoming PauseTask request")
+	p.toggleTask(w, r, "pauseTask", "Pause", p.PaymentRecordUC.PauseTask)
+}
+
+// ResumeTask godoc
+// @Summary      Resume polling task
+// @Description  Resume checks for a paused payment record and trigger an immediate check
+// @Tags         payment_records
+// @Security     BearerAuth
+// @Param        id   path      string  true  "UUID of the payment record"
+// @Success      200  {object}  response.APIResponse
+// @Failure      401  {object}  response.APIResponse  "Unauthorized"
+// @Failure      404  {object}  response.APIResponse  "Task not found"
+// @Failure      422  {object}  response.APIResponse  "Invalid UUID"
+// @Failure      500  {object}  response.APIResponse  "Internal server error"
+// @Router       /api/v1/payment-records/check/tasks/{id}/resume [post]
+func (p *PaymentRecordHandler) ResumeTask(w http.ResponseWriter, r *http.Request) {
+	p.Logger.Info().Msg("📥 Incoming ResumeTask request")
+	p.toggleTask(w, r, "resumeTask", "Resume", p.PaymentRecordUC.ResumeTask)
+}
+
+// PauseAll godoc
+// @Summary      Pause all polling
+// @Description  Suspend checks for every polling task on all instances (e.g. payment server maintenance)
+// @Tags         payment_records
+// @Security     BearerAuth
+// @Success      200  {object}  response.APIResponse
+// @Failure      401  {object}  response.APIResponse  "Unauthorized"
+// @Failure      500  {object}  response.APIResponse  "Internal server error"
+// @Router       /api/v1/payment-records/check/tasks/pause [post]
+func (p *PaymentRecordHandler) PauseAll(w http.ResponseWriter, r *http.Request) {
+	p.Logger.Info().Msg("📥 Incoming PauseAll request")
+	if err := p.PaymentRecordUC.PauseAll(r.Context()); err != nil {
+		p.Logger.Error().Err(err).Msg("❌ Failed to pause polling")
+		response.Failed(w, 500, "paymentRecords", "pauseAll", "Error Pause Polling")
+		return
+	}
+	p.Logger.Info().Msg("✅ Successfully paused polling")
+	response.Success(w, 200, "paymentRecords", "pauseAll", "Success Pause Polling", nil)
+}
+
+// ResumeAll godoc
+// @Summary      Resume all polling
+// @Description  Resume checks for every polling task after a global pause
+// @Tags         payment_records
+// @Security     BearerAuth
+// @Success      200  {object}  response.APIResponse
+// @Failure      401  {object}  response.APIResponse  "Unauthorized"
+// @Failure      500  {object}  response.APIResponse  "Internal server error"
+// @Router       /api/v1/payment-records/check/tasks/resume [post]
+func (p *PaymentRecordHandler) ResumeAll(w http.ResponseWriter, r *http.Request) {
+	p.Logger.Info().Msg("📥 Incoming ResumeAll request")
+	if err := p.PaymentRecordUC.ResumeAll(r.Context()); err != nil {
+		p.Logger.Error().Err(err).Msg("❌ Failed to resume polling")
+		response.Failed(w, 500, "paymentRecords", "resumeAll", "Error Resume Polling")
+		return
+	}
+	p.Logger.Info().Msg("✅ Successfully resumed polling")
+	response.Success(w, 200, "paymentRecords", "resumeAll", "Success Resume Polling", nil)
+}
+
+func (p *PaymentRecordHandler) toggleTask(w http.ResponseWriter, r *http.Request, state string, action string, toggle func(ctx context.Context, id uuid.UUID) error) {
+	id, err := uuid.Parse(router.GetParam(r, "id"))
+	if err != nil {
+		p.Logger.Error().Err(err).Msg("❌ Invalid UUID parameter")
+		response.Failed(w, 422, "paymentRecords", state, "Invalid UUID")
+		return
+	}
+
+	if err := toggle(r.Context(), id); err != nil {
+		if errors.Is(err, usecase.ErrPollingTaskNotFound) {
+			p.Logger.Warn().Err(err).Msg("‼️ Polling task not found")
+			response.Failed(w, 404, "paymentRecords", state, "Polling Task Not Found")
+			return
+		}
+		p.Logger.Error().Err(err).Msgf("❌ Failed to %s polling task", state)
+		response.Failed(w, 500, "paymentRecords", state, "Error "+action+" Polling Task")
+		return
+	}
+
+	p.Logger.Info().Str("payment_id", id.String()).Msgf("✅ Successfully %s", state)
+	response.Success(w, 200, "paymentRecords", state, "Success "+action+" Polling Task", []uuid.UUID{id})
+}
@@ -1,20 +1,25 @@
 package http
 
 import (
+	"beta-payment-api-client/internal/delivery/http/check_log"
 	"beta-payment-api-client/internal/delivery/http/health"
 	"beta-payment-api-client/internal/delivery/http/middleware"
 	"beta-payment-api-client/internal/delivery/http/payment_record"
 	"beta-payment-api-client/internal/delivery/http/router"
+	"beta-payment-api-client/internal/pkg/healthcheck"
+	"beta-payment-api-client/internal/pkg/payment_provider"
 	"beta-payment-api-client/internal/usecase"
+	"expvar"
 	"github.com/rs/zerolog"
 
 	"github.com/swaggo/http-swagger"
 	"net/http"
 )
 
-func SetupHandler(paymentRecordUC usecase.PaymentRecordUseCase, logger zerolog.Logger) http.Handler {
-	paymentRecordHandler := payment_record.PaymentRecordHandler{paymentRecordUC, logger}
-	healthHandler := health.HealthHandler{logger}
+func SetupHandler(paymentRecordUC usecase.PaymentRecordUseCase, checkLogReplayUC usecase.CheckLogReplayUseCase, paymentProviders *payment_provider.Registry, readiness *healthcheck.Checker, logger zerolog.Logger) http.Handler {
+	paymentRecordHandler := payment_record.NewPaymentRecordHandler(paymentRecordUC, logger)
+	checkLogHandler := check_log.NewCheckLogHandler(checkLogReplayUC, logger)
+	healthHandler := health.NewHealthHandler(paymentProviders, readiness, logger)
 	auth := middleware.AuthMiddleware(logger)
 	log := middleware.LoggingMiddleware(logger)
 
@@ -23,9 +28,22 @@ func SetupHandler(paymentRecordUC usecase.PaymentRecordUseCase, logger zerolog.L
 	r.HandlePrefix(http.MethodGet, "/swagger/", httpSwagger.WrapHandler)
 
 	r.Handle("GET", "/healthz", middleware.Chain(log)(healthHandler.Check))
-
-	//r.Handle("GET", "/api/v1/payment-records/check/histories/{id}", middleware.Chain(log, auth)(paymentRecordHandler.))
+	r.Handle("GET", "/livez", healthHandler.Live)
+	r.Handle("GET", "/readyz", healthHandler.Ready)
+	r.Handle("GET", "/debug/vars", middleware.Chain(log, auth)(expvar.Handler().ServeHTTP))
+
+	// ⚠️ Router mencocokkan prefix (pattern + "(/.*)?"), jadi route yang lebih spesifik harus didaftarkan lebih dulu
+	r.Handle("POST", "/api/v1/payment-records/check/logs/{id}/replay", middleware.Chain(log, auth)(checkLogHandler.Replay))
+	r.Handle("GET", "/api/v1/payment-records/check/histories/{id}", middleware.Chain(log, auth)(paymentRecordHandler.CheckHistoryByID))
+	r.Handle("POST", "/api/v1/payment-records/check/tasks/pause", middleware.Chain(log, auth)(paymentRecordHandler.PauseAll))
+	r.Handle("POST", "/api/v1/payment-records/check/tasks/resume", middleware.Chain(log, auth)(paymentRecordHandler.ResumeAll))
+	r.Handle("POST", "/api/v1/payment-records/check/tasks/{id}/pause", middleware.Chain(log, auth)(paymentRecordHandler.PauseTask))
+	r.Handle("POST", "/api/v1/payment-records/check/tasks/{id}/resume", middleware.Chain(log, auth)(paymentRecordHandler.ResumeTask))
+	r.Handle("POST", "/api/v1/payment-records/check/tasks/{id}/boost", middleware.Chain(log, auth)(paymentRecordHandler.BoostTask))
 	r.Handle("POST", "/api/v1/payment-records/check", middleware.Chain(log, auth)(paymentRecordHandler.CheckByID))
+	r.Handle("DELETE", "/api/v1/payment-records/check/tasks/{id}", middleware.Chain(log, auth)(paymentRecordHandler.CancelTask))
+	r.Handle("DELETE", "/api/v1/payment-records/check/tasks", middleware.Chain(log, auth)(paymentRecordHandler.CancelTasksByTag))
+	r.Handle("GET", "/api/v1/payment-records/check/tasks/{id}", middleware.Chain(log, auth)(paymentRecordHandler.GetTaskByID))
 	r.Handle("GET", "/api/v1/payment-records/check/tasks", middleware.Chain(log, auth)(paymentRecordHandler.GetAllTask))
 
 	return r
@@ -0,0 +1,93 @@
+package request
+
+import (
+	"beta-payment-api-client/internal/entity"
+	"fmt"
+	"github.com/google/uuid"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const maxCheckHistoryPerPage = 100
+
+type CheckHistoryListQueryParams struct {
+	From       string `json:"from,omitempty"`
+	To         string `json:"to,omitempty"`
+	SortDir    string `json:"sort_direction"`
+	Cursor     string `json:"cursor,omitempty"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	Page       int    `json:"page"`
+	PerPage    int    `json:"per_page"`
+	Total      int    `json:"total"`
+}
+
+func ParseCheckHistoryQueryParams(r *http.Request) CheckHistoryListQueryParams {
+	q := r.URL.Query()
+
+	// Pagination
+	page, _ := strconv.Atoi(q.Get("page"))
+	perPage, _ := strconv.Atoi(q.Get("per_page"))
+	if page <= 0 {
+		page = 1
+	}
+	if perPage <= 0 {
+		perPage = 10
+	}
+	if perPage > maxCheckHistoryPerPage {
+		perPage = maxCheckHistoryPerPage
+	}
+
+	sortDir := strings.ToUpper(q.Get("sort_direction"))
+	if sortDir != "ASC" {
+		sortDir = "DESC"
+	}
+
+	return CheckHistoryListQueryParams{
+		From:    q.Get("from"),
+		To:      q.Get("to"),
+		SortDir: sortDir,
+		Cursor:  q.Get("cursor"),
+		Page:    page,
+		PerPage: perPage,
+	}
+}
+
+// Filter mengubah query params menjadi filter usecase; from/to memakai format RFC3339.
+func (p CheckHistoryListQueryParams) Filter(paymentID uuid.UUID) (entity.CheckHistoryFilter, error) {
+	filter := entity.CheckHistoryFilter{
+		PaymentID: paymentID,
+		Ascending: p.SortDir == "ASC",
+		Page:      p.Page,
+		PerPage:   p.PerPage,
+	}
+
+	var err error
+	if filter.From, err = parseTime("from", p.From); err != nil {
+		return filter, err
+	}
+	if filter.To, err = parseTime("to", p.To); err != nil {
+		return filter, err
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		return filter, fmt.Errorf("from must be before to")
+	}
+	if p.Cursor != "" {
+		if filter.Cursor, err = entity.ParseCheckHistoryCursor(p.Cursor); err != nil {
+			return filter, err
+		}
+	}
+	return filter, nil
+}
+
+func parseTime(name string, value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s: %q", name, value)
+	}
+	return t.UTC(), nil
+}
@@ -0,0 +1,101 @@
+package request
+
+import (
+	"beta-payment-api-client/internal/entity"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestParseCheckHistoryQueryParams(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  CheckHistoryListQueryParams
+	}{
+		{name: "defaults", query: "", want: CheckHistoryListQueryParams{SortDir: "DESC", Page: 1, PerPage: 10}},
+		{name: "ascending is case-insensitive", query: "sort_direction=asc", want: CheckHistoryListQueryParams{SortDir: "ASC", Page: 1, PerPage: 10}},
+		{name: "unknown direction falls back to desc", query: "sort_direction=sideways", want: CheckHistoryListQueryParams{SortDir: "DESC", Page: 1, PerPage: 10}},
+		{name: "per page is capped", query: "page=3&per_page=1000", want: CheckHistoryListQueryParams{SortDir: "DESC", Page: 3, PerPage: maxCheckHistoryPerPage}},
+		{name: "invalid pagination uses defaults", query: "page=-1&per_page=abc", want: CheckHistoryListQueryParams{SortDir: "DESC", Page: 1, PerPage: 10}},
+		{
+			name:  "range and cursor are passed through",
+			query: "from=2025-01-01T00:00:00Z&to=2025-02-01T00:00:00Z&cursor=abc",
+			want:  CheckHistoryListQueryParams{From: "2025-01-01T00:00:00Z", To: "2025-02-01T00:00:00Z", Cursor: "abc", SortDir: "DESC", Page: 1, PerPage: 10},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/api/v1/payment-records/x/checks?"+tt.query, nil)
+			if got := ParseCheckHistoryQueryParams(r); got != tt.want {
+				t.Errorf("ParseCheckHistoryQueryParams() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckHistoryListQueryParamsFilter(t *testing.T) {
+	paymentID := uuid.New()
+	cursor := entity.CheckHistoryCursor{OccurredAt: time.Date(2025, 1, 15, 8, 0, 0, 0, time.UTC), ID: uuid.New()}
+
+	tests := []struct {
+		name       string
+		params     CheckHistoryListQueryParams
+		wantFrom   time.Time
+		wantTo     time.Time
+		wantAsc    bool
+		wantCursor *entity.CheckHistoryCursor
+		wantErr    bool
+		wantErrIs  error
+	}{
+		{name: "no range", params: CheckHistoryListQueryParams{SortDir: "DESC", Page: 1, PerPage: 10}},
+		{name: "ascending", params: CheckHistoryListQueryParams{SortDir: "ASC", Page: 1, PerPage: 10}, wantAsc: true},
+		{
+			name:     "range converted to utc",
+			params:   CheckHistoryListQueryParams{From: "2025-01-01T07:00:00+07:00", To: "2025-02-01T00:00:00Z", SortDir: "DESC"},
+			wantFrom: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+			wantTo:   time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{name: "only from", params: CheckHistoryListQueryParams{From: "2025-01-01T00:00:00Z"}, wantFrom: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "invalid from", params: CheckHistoryListQueryParams{From: "2025-01-01"}, wantErr: true},
+		{name: "invalid to", params: CheckHistoryListQueryParams{To: "tomorrow"}, wantErr: true},
+		{name: "from equal to to", params: CheckHistoryListQueryParams{From: "2025-01-01T00:00:00Z", To: "2025-01-01T00:00:00Z"}, wantErr: true},
+		{name: "from after to", params: CheckHistoryListQueryParams{From: "2025-02-01T00:00:00Z", To: "2025-01-01T00:00:00Z"}, wantErr: true},
+		{name: "valid cursor", params: CheckHistoryListQueryParams{Cursor: cursor.Encode()}, wantCursor: &cursor},
+		{name: "invalid cursor", params: CheckHistoryListQueryParams{Cursor: "garbage!"}, wantErr: true, wantErrIs: entity.ErrInvalidCheckHistoryCursor},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := tt.params.Filter(paymentID)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Filter() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErrIs != nil && !errors.Is(err, tt.wantErrIs) {
+				t.Errorf("Filter() error = %v, want %v", err, tt.wantErrIs)
+			}
+			if err != nil {
+				return
+			}
+			if filter.PaymentID != paymentID || filter.Page != tt.params.Page || filter.PerPage != tt.params.PerPage {
+				t.Errorf("filter = %+v, want payment %s page %d per page %d", filter, paymentID, tt.params.Page, tt.params.PerPage)
+			}
+			if !filter.From.Equal(tt.wantFrom) || !filter.To.Equal(tt.wantTo) {
+				t.Errorf("range = [%s, %s), want [%s, %s)", filter.From, filter.To, tt.wantFrom, tt.wantTo)
+			}
+			if filter.Ascending != tt.wantAsc {
+				t.Errorf("Ascending = %v, want %v", filter.Ascending, tt.wantAsc)
+			}
+			switch {
+			case tt.wantCursor == nil && filter.Cursor != nil:
+				t.Errorf("Cursor = %+v, want nil", filter.Cursor)
+			case tt.wantCursor != nil && (filter.Cursor == nil || !filter.Cursor.OccurredAt.Equal(tt.wantCursor.OccurredAt) || filter.Cursor.ID != tt.wantCursor.ID):
+				t.Errorf("Cursor = %+v, want %+v", filter.Cursor, tt.wantCursor)
+			}
+		})
+	}
+}
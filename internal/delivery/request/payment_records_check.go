@@ -1,16 +1,23 @@
 package request
 
 import (
+	"beta-payment-api-client/internal/entity"
 	"errors"
 )
 
 type CheckPaymentRecord struct {
-	ID string `json:"id"`
+	ID            string                        `json:"id"`
+	Tag           string                        `json:"tag"`
+	Provider      string                        `json:"provider,omitempty"` // kosong = routing lewat tag
+	PollingPolicy *entity.PollingPolicyOverride `json:"polling_policy,omitempty"`
 }
 
 func (r *CheckPaymentRecord) Validate() error {
 	if r.ID == "" {
 		return errors.New("id is required")
 	}
+	if r.PollingPolicy != nil {
+		return r.PollingPolicy.Validate()
+	}
 	return nil
 }
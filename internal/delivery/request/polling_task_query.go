@@ -0,0 +1,70 @@
+package request
+
+import (
+	"beta-payment-api-client/internal/entity"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type PollingTaskListQueryParams struct {
+	Status  string `json:"status,omitempty"`
+	MinAge  string `json:"min_age,omitempty"`
+	MaxAge  string `json:"max_age,omitempty"`
+	Page    int    `json:"page"`
+	PerPage int    `json:"per_page"`
+	Total   int    `json:"total"`
+}
+
+func ParsePollingTaskQueryParams(r *http.Request) PollingTaskListQueryParams {
+	q := r.URL.Query()
+
+	// Pagination
+	page, _ := strconv.Atoi(q.Get("page"))
+	perPage, _ := strconv.Atoi(q.Get("per_page"))
+	if page <= 0 {
+		page = 1
+	}
+	if perPage <= 0 {
+		perPage = 10
+	}
+
+	return PollingTaskListQueryParams{
+		Status:  strings.ToUpper(q.Get("status")),
+		MinAge:  q.Get("min_age"),
+		MaxAge:  q.Get("max_age"),
+		Page:    page,
+		PerPage: perPage,
+	}
+}
+
+// Filter mengubah query params menjadi filter usecase; umur memakai format durasi Go (mis. "30m", "2h").
+func (p PollingTaskListQueryParams) Filter() (entity.PollingTaskFilter, error) {
+	filter := entity.PollingTaskFilter{
+		Status:  p.Status,
+		Page:    p.Page,
+		PerPage: p.PerPage,
+	}
+
+	var err error
+	if filter.MinAge, err = parseAge("min_age", p.MinAge); err != nil {
+		return filter, err
+	}
+	if filter.MaxAge, err = parseAge("max_age", p.MaxAge); err != nil {
+		return filter, err
+	}
+	return filter, nil
+}
+
+func parseAge(name string, value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	age, err := time.ParseDuration(value)
+	if err != nil || age < 0 {
+		return 0, fmt.Errorf("invalid %s: %q", name, value)
+	}
+	return age, nil
+}
@@ -0,0 +1,66 @@
+package request
+
+import (
+	"beta-payment-api-client/internal/entity"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParsePollingTaskQueryParams(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  PollingTaskListQueryParams
+	}{
+		{name: "defaults", query: "", want: PollingTaskListQueryParams{Page: 1, PerPage: 10}},
+		{name: "status is upper-cased", query: "status=paused", want: PollingTaskListQueryParams{Status: "PAUSED", Page: 1, PerPage: 10}},
+		{name: "invalid pagination uses defaults", query: "page=0&per_page=-5", want: PollingTaskListQueryParams{Page: 1, PerPage: 10}},
+		{
+			name:  "ages and pagination are passed through",
+			query: "min_age=30m&max_age=2h&page=2&per_page=50",
+			want:  PollingTaskListQueryParams{MinAge: "30m", MaxAge: "2h", Page: 2, PerPage: 50},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/api/v1/polling-tasks?"+tt.query, nil)
+			if got := ParsePollingTaskQueryParams(r); got != tt.want {
+				t.Errorf("ParsePollingTaskQueryParams() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPollingTaskListQueryParamsFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  PollingTaskListQueryParams
+		want    entity.PollingTaskFilter
+		wantErr bool
+	}{
+		{name: "no filter", params: PollingTaskListQueryParams{Page: 1, PerPage: 10}, want: entity.PollingTaskFilter{Page: 1, PerPage: 10}},
+		{
+			name:   "status and ages",
+			params: PollingTaskListQueryParams{Status: "RUNNING", MinAge: "30m", MaxAge: "2h", Page: 2, PerPage: 20},
+			want:   entity.PollingTaskFilter{Status: "RUNNING", MinAge: 30 * time.Minute, MaxAge: 2 * time.Hour, Page: 2, PerPage: 20},
+		},
+		{name: "zero age", params: PollingTaskListQueryParams{MinAge: "0s"}, want: entity.PollingTaskFilter{}},
+		{name: "invalid min age", params: PollingTaskListQueryParams{MinAge: "30"}, wantErr: true},
+		{name: "negative min age", params: PollingTaskListQueryParams{MinAge: "-1h"}, wantErr: true},
+		{name: "invalid max age", params: PollingTaskListQueryParams{MaxAge: "two hours"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.params.Filter()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Filter() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got != tt.want {
+				t.Errorf("Filter() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package entity
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/google/uuid"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// ReplayResponse status + body (JSON di-decode, selain itu string) dari satu response.
+type ReplayResponse struct {
+	StatusCode int `json:"status_code"`
+	Body       any `json:"body"`
+}
+
+// JSONChange satu perbedaan field antara body lama dan baru; Old / New nil = field tidak ada.
+type JSONChange struct {
+	Path string `json:"path"`
+	Old  any    `json:"old"`
+	New  any    `json:"new"`
+}
+
+// CheckLogReplay hasil replay request dari satu row check log.
+type CheckLogReplay struct {
+	CheckLogID    uuid.UUID      `json:"check_log_id"`
+	PaymentID     uuid.UUID      `json:"payment_id"`
+	Provider      string         `json:"provider"`
+	Method        string         `json:"method"`
+	URL           string         `json:"url"`
+	OccurredAt    *time.Time     `json:"occurred_at"`
+	ReplayedAt    time.Time      `json:"replayed_at"`
+	Original      ReplayResponse `json:"original"`
+	Replayed      ReplayResponse `json:"replayed"`
+	StatusChanged bool           `json:"status_changed"`
+	BodyChanged   bool           `json:"body_changed"`
+	BodyDiff      []JSONChange   `json:"body_diff,omitempty"` // hanya kalau kedua body JSON
+}
+
+// NewCheckLogReplay membandingkan response tercatat dengan response replay.
+func NewCheckLogReplay(log PaymentRecordCheckLog, provider string, statusCode int, body []byte) CheckLogReplay {
+	replay := CheckLogReplay{
+		CheckLogID:    log.ID,
+		PaymentID:     log.PaymentID,
+		Provider:      provider,
+		Method:        log.Method,
+		URL:           log.URL,
+		OccurredAt:    log.OccurredAt,
+		ReplayedAt:    time.Now(),
+		Original:      ReplayResponse{StatusCode: log.StatusCode, Body: decodeBody(log.ResponseBody)},
+		Replayed:      ReplayResponse{StatusCode: statusCode, Body: decodeBody(body)},
+		StatusChanged: log.StatusCode != statusCode,
+	}
+
+	oldJSON, oldOK := parseJSON(log.ResponseBody)
+	newJSON, newOK := parseJSON(body)
+	if oldOK && newOK {
+		diffJSON("", oldJSON, newJSON, &replay.BodyDiff)
+		replay.BodyChanged = len(replay.BodyDiff) > 0
+	} else {
+		replay.BodyChanged = !bytes.Equal(bytes.TrimSpace(log.ResponseBody), bytes.TrimSpace(body))
+	}
+	return replay
+}
+
+func decodeBody(body []byte) any {
+	if len(body) == 0 {
+		return nil
+	}
+	if json.Valid(body) {
+		return json.RawMessage(body)
+	}
+	return string(body)
+}
+
+func parseJSON(body []byte) (any, bool) {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil, false
+	}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var document any
+	if err := decoder.Decode(&document); err != nil || decoder.More() {
+		return nil, false
+	}
+	return document, true
+}
+
+// diffJSON mencatat perbedaan per field (path bertitik, index array dalam angka), terurut.
+func diffJSON(path string, old, new any, changes *[]JSONChange) {
+	oldObject, oldIsObject := old.(map[string]any)
+	newObject, newIsObject := new.(map[string]any)
+	if oldIsObject && newIsObject {
+		keys := map[string]bool{}
+		for key := range oldObject {
+			keys[key] = true
+		}
+		for key := range newObject {
+			keys[key] = true
+		}
+		sorted := make([]string, 0, len(keys))
+		for key := range keys {
+			sorted = append(sorted, key)
+		}
+		sort.Strings(sorted)
+		for _, key := range sorted {
+			diffJSON(joinPath(path, key), oldObject[key], newObject[key], changes)
+		}
+		return
+	}
+
+	oldArray, oldIsArray := old.([]any)
+	newArray, newIsArray := new.([]any)
+	if oldIsArray && newIsArray {
+		for i := 0; i < len(oldArray) || i < len(newArray); i++ {
+			var oldItem, newItem any
+			if i < len(oldArray) {
+				oldItem = oldArray[i]
+			}
+			if i < len(newArray) {
+				newItem = newArray[i]
+			}
+			diffJSON(joinPath(path, strconv.Itoa(i)), oldItem, newItem, changes)
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(old, new) {
+		*changes = append(*changes, JSONChange{Path: path, Old: old, New: new})
+	}
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -2,15 +2,112 @@ package entity
 
 import (
 	"beta-payment-api-client/internal/valueobject"
+	"errors"
+	"fmt"
 	"github.com/google/uuid"
+	"sort"
 	"time"
 )
 
 type PaymentStatus string
 
+const (
+	PaymentStatusPending   PaymentStatus = "PENDING"
+	PaymentStatusPaid      PaymentStatus = "PAID"
+	PaymentStatusUnpaid    PaymentStatus = "UNPAID"
+	PaymentStatusFailed    PaymentStatus = "FAILED"
+	PaymentStatusExpired   PaymentStatus = "EXPIRED"
+	PaymentStatusCancelled PaymentStatus = "CANCELLED"
+	PaymentStatusTimedOut  PaymentStatus = "TIMED_OUT"
+	PaymentStatusNotFound  PaymentStatus = "NOT_FOUND"
+)
+
+var ErrInvalidStatusTransition = errors.New("invalid payment status transition")
+
+// paymentStatusTransitions: status asal → status tujuan yang diizinkan.
+// Status final (PAID, UNPAID, FAILED, EXPIRED, CANCELLED, NOT_FOUND) tidak punya transisi keluar.
+// TIMED_OUT masih bisa dilanjutkan (re-check) atau diselesaikan belakangan.
+var paymentStatusTransitions = map[PaymentStatus][]PaymentStatus{
+	PaymentStatusPending: {
+		PaymentStatusPaid, PaymentStatusUnpaid, PaymentStatusFailed,
+		PaymentStatusExpired, PaymentStatusCancelled, PaymentStatusTimedOut, PaymentStatusNotFound,
+	},
+	PaymentStatusTimedOut: {
+		PaymentStatusPending, PaymentStatusPaid, PaymentStatusUnpaid, PaymentStatusFailed,
+		PaymentStatusExpired, PaymentStatusCancelled, PaymentStatusNotFound,
+	},
+	PaymentStatusPaid:      {},
+	PaymentStatusUnpaid:    {},
+	PaymentStatusFailed:    {},
+	PaymentStatusExpired:   {},
+	PaymentStatusCancelled: {},
+	PaymentStatusNotFound:  {},
+}
+
+// ParsePaymentStatus memvalidasi status mentah (mis. dari payment server).
+func ParsePaymentStatus(raw string) (PaymentStatus, error) {
+	status := PaymentStatus(raw)
+	if !status.IsValid() {
+		return "", fmt.Errorf("unknown payment status: %q", raw)
+	}
+	return status, nil
+}
+
+func (s PaymentStatus) IsValid() bool {
+	_, ok := paymentStatusTransitions[s]
+	return ok
+}
+
+// IsFinal true jika status sudah final dan payment tidak perlu di-poll lagi.
+func (s PaymentStatus) IsFinal() bool {
+	next, ok := paymentStatusTransitions[s]
+	return ok && len(next) == 0
+}
+
+// FinalPaymentStatuses mengembalikan semua status final, terurut.
+func FinalPaymentStatuses() []PaymentStatus {
+	var statuses []PaymentStatus
+	for status := range paymentStatusTransitions {
+		if status.IsFinal() {
+			statuses = append(statuses, status)
+		}
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i] < statuses[j] })
+	return statuses
+}
+
+// CanTransitionTo mengecek apakah perpindahan s → next diizinkan.
+// Status kosong (record lama sebelum ada state machine) diperlakukan sebagai PENDING;
+// transisi ke status yang sama dianggap no-op yang valid.
+func (s PaymentStatus) CanTransitionTo(next PaymentStatus) bool {
+	if s == "" {
+		s = PaymentStatusPending
+	}
+	if !s.IsValid() || !next.IsValid() {
+		return false
+	}
+	if s == next {
+		return true
+	}
+	for _, allowed := range paymentStatusTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+func (s PaymentStatus) ValidateTransition(next PaymentStatus) error {
+	if !s.CanTransitionTo(next) {
+		return fmt.Errorf("%w: %s -> %s", ErrInvalidStatusTransition, s, next)
+	}
+	return nil
+}
+
 type PaymentRecord struct {
 	ID          uuid.UUID            `json:"id"`
 	Tag         string               `json:"tag"`
+	Provider    string               `json:"provider"`
 	Description string               `json:"description"`
 	Amount      valueobject.BigFloat `json:"amount"`
 	Status      PaymentStatus        `json:"status"`
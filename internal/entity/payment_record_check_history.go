@@ -0,0 +1,91 @@
+package entity
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"github.com/google/uuid"
+	"strings"
+	"time"
+)
+
+var ErrInvalidCheckHistoryCursor = errors.New("invalid check history cursor")
+
+// CheckHistoryFilter filter + paginasi untuk riwayat cek satu payment.
+// Kalau Cursor di-set, paginasi memakai keyset (occurred_at, id) dan Page diabaikan.
+type CheckHistoryFilter struct {
+	PaymentID uuid.UUID
+	From      time.Time // zero = tanpa batas bawah (inklusif)
+	To        time.Time // zero = tanpa batas atas (eksklusif)
+	Ascending bool      // default: terbaru lebih dulu
+	Cursor    *CheckHistoryCursor
+	Page      int
+	PerPage   int
+}
+
+// CheckHistoryCursor posisi row terakhir yang sudah dikirim ke client.
+type CheckHistoryCursor struct {
+	OccurredAt time.Time
+	ID         uuid.UUID
+}
+
+// Encode mengubah cursor jadi token opaque untuk query param "cursor".
+func (c CheckHistoryCursor) Encode() string {
+	raw := c.OccurredAt.UTC().Format(time.RFC3339Nano) + "|" + c.ID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func ParseCheckHistoryCursor(token string) (*CheckHistoryCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCheckHistoryCursor
+	}
+	occurredAt, id, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return nil, ErrInvalidCheckHistoryCursor
+	}
+
+	cursor := &CheckHistoryCursor{}
+	if cursor.OccurredAt, err = time.Parse(time.RFC3339Nano, occurredAt); err != nil {
+		return nil, ErrInvalidCheckHistoryCursor
+	}
+	if cursor.ID, err = uuid.Parse(id); err != nil {
+		return nil, ErrInvalidCheckHistoryCursor
+	}
+	return cursor, nil
+}
+
+// PaymentRecordCheckAttempt satu percobaan cek yang ditampilkan ke client;
+// body response di-decode kalau JSON, selain itu dikirim sebagai string.
+type PaymentRecordCheckAttempt struct {
+	ID              uuid.UUID       `json:"id"`
+	OccurredAt      *time.Time      `json:"occurred_at"`
+	Method          string          `json:"method"`
+	URL             string          `json:"url"`
+	StatusCode      int             `json:"status_code"`
+	DelaySeconds    int64           `json:"delay_seconds"`
+	RequestHeaders  json.RawMessage `json:"request_headers"`
+	ResponseHeaders json.RawMessage `json:"response_headers"`
+	ResponseBody    any             `json:"response_body"`
+}
+
+func NewPaymentRecordCheckAttempt(log PaymentRecordCheckLog) PaymentRecordCheckAttempt {
+	attempt := PaymentRecordCheckAttempt{
+		ID:              log.ID,
+		OccurredAt:      log.OccurredAt,
+		Method:          log.Method,
+		URL:             log.URL,
+		StatusCode:      log.StatusCode,
+		DelaySeconds:    log.DelaySeconds,
+		RequestHeaders:  log.RequestHeaders,
+		ResponseHeaders: log.ResponseHeaders,
+	}
+	if len(log.ResponseBody) > 0 {
+		if json.Valid(log.ResponseBody) {
+			attempt.ResponseBody = json.RawMessage(log.ResponseBody)
+		} else {
+			attempt.ResponseBody = string(log.ResponseBody)
+		}
+	}
+	return attempt
+}
@@ -0,0 +1,58 @@
+package entity
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestCheckHistoryCursorRoundTrip(t *testing.T) {
+	id := uuid.MustParse("6f1c2d3e-4a5b-4c6d-8e7f-9a0b1c2d3e4f")
+	tests := []struct {
+		name       string
+		occurredAt time.Time
+	}{
+		{name: "utc with nanoseconds", occurredAt: time.Date(2025, 3, 14, 15, 9, 26, 535897932, time.UTC)},
+		{name: "whole seconds", occurredAt: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "non-utc offset is normalized", occurredAt: time.Date(2025, 6, 1, 7, 0, 0, 1000, time.FixedZone("WIB", 7*3600))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := CheckHistoryCursor{OccurredAt: tt.occurredAt, ID: id}.Encode()
+			got, err := ParseCheckHistoryCursor(token)
+			if err != nil {
+				t.Fatalf("ParseCheckHistoryCursor(%q): %v", token, err)
+			}
+			if !got.OccurredAt.Equal(tt.occurredAt) || got.ID != id {
+				t.Errorf("cursor = %+v, want occurred_at %s id %s", got, tt.occurredAt, id)
+			}
+		})
+	}
+}
+
+func TestParseCheckHistoryCursorInvalid(t *testing.T) {
+	encode := func(raw string) string { return base64.RawURLEncoding.EncodeToString([]byte(raw)) }
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "empty", token: ""},
+		{name: "not base64", token: "not a cursor!"},
+		{name: "missing separator", token: encode("2025-01-01T00:00:00Z")},
+		{name: "invalid time", token: encode("yesterday|6f1c2d3e-4a5b-4c6d-8e7f-9a0b1c2d3e4f")},
+		{name: "invalid id", token: encode("2025-01-01T00:00:00Z|42")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseCheckHistoryCursor(tt.token); !errors.Is(err, ErrInvalidCheckHistoryCursor) {
+				t.Errorf("ParseCheckHistoryCursor(%q) error = %v, want %v", tt.token, err, ErrInvalidCheckHistoryCursor)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package entity
+
+import (
+	"strings"
+	"time"
+)
+
+const checkLogPartitionPrefix = "payment_record_check_logs_p"
+
+// CheckLogPartition satu partisi bulanan payment_record_check_logs: [From, To).
+type CheckLogPartition struct {
+	Name string    `json:"name"`
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+// NewCheckLogPartition membuat partisi untuk bulan yang memuat t (UTC).
+func NewCheckLogPartition(t time.Time) CheckLogPartition {
+	t = t.UTC()
+	from := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return CheckLogPartition{
+		Name: checkLogPartitionPrefix + from.Format("200601"),
+		From: from,
+		To:   from.AddDate(0, 1, 0),
+	}
+}
+
+// UpcomingCheckLogPartitions partisi bulan yang memuat now ditambah ahead bulan berikutnya.
+// Bulan dihitung dari tanggal 1: AddDate dari tanggal 29–31 bisa melompati bulan (31 Jan + 1 bulan = 3 Mar).
+func UpcomingCheckLogPartitions(now time.Time, ahead int) []CheckLogPartition {
+	now = now.UTC()
+	first := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	partitions := make([]CheckLogPartition, 0, ahead+1)
+	for i := 0; i <= ahead; i++ {
+		partitions = append(partitions, NewCheckLogPartition(first.AddDate(0, i, 0)))
+	}
+	return partitions
+}
+
+// ParseCheckLogPartition membaca partisi dari nama tabel (payment_record_check_logs_pYYYYMM);
+// false untuk partisi lain, mis. partisi default.
+func ParseCheckLogPartition(name string) (CheckLogPartition, bool) {
+	month, ok := strings.CutPrefix(name, checkLogPartitionPrefix)
+	if !ok {
+		return CheckLogPartition{}, false
+	}
+	from, err := time.Parse("200601", month)
+	if err != nil {
+		return CheckLogPartition{}, false
+	}
+	return NewCheckLogPartition(from), true
+}
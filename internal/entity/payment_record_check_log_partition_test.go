@@ -0,0 +1,99 @@
+package entity
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewCheckLogPartition(t *testing.T) {
+	jakarta := time.FixedZone("WIB", 7*3600)
+	tests := []struct {
+		name     string
+		t        time.Time
+		wantName string
+		wantFrom time.Time
+		wantTo   time.Time
+	}{
+		{"mid month", time.Date(2026, 3, 15, 10, 0, 0, 0, time.UTC), "payment_record_check_logs_p202603",
+			time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"first instant", time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), "payment_record_check_logs_p202603",
+			time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"december rolls year", time.Date(2026, 12, 31, 23, 59, 59, 0, time.UTC), "payment_record_check_logs_p202612",
+			time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC), time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"non-UTC input uses UTC month", time.Date(2026, 4, 1, 3, 0, 0, 0, jakarta), "payment_record_check_logs_p202603",
+			time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewCheckLogPartition(tt.t)
+			if got.Name != tt.wantName || !got.From.Equal(tt.wantFrom) || !got.To.Equal(tt.wantTo) {
+				t.Errorf("NewCheckLogPartition(%s) = %+v, want %s [%s, %s)", tt.t, got, tt.wantName, tt.wantFrom, tt.wantTo)
+			}
+		})
+	}
+}
+
+func TestUpcomingCheckLogPartitions(t *testing.T) {
+	tests := []struct {
+		name  string
+		now   time.Time
+		ahead int
+		want  []string
+	}{
+		{"mid month", time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC), 2,
+			[]string{"p202601", "p202602", "p202603"}},
+		{"jan 31 keeps february", time.Date(2026, 1, 31, 12, 0, 0, 0, time.UTC), 2,
+			[]string{"p202601", "p202602", "p202603"}},
+		{"jan 29 non-leap year", time.Date(2026, 1, 29, 0, 0, 0, 0, time.UTC), 1,
+			[]string{"p202601", "p202602"}},
+		{"jan 30 leap year", time.Date(2028, 1, 30, 0, 0, 0, 0, time.UTC), 1,
+			[]string{"p202801", "p202802"}},
+		{"march 31 keeps april", time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC), 3,
+			[]string{"p202603", "p202604", "p202605", "p202606"}},
+		{"year end", time.Date(2026, 12, 31, 23, 0, 0, 0, time.UTC), 2,
+			[]string{"p202612", "p202701", "p202702"}},
+		{"no partitions ahead", time.Date(2026, 8, 31, 0, 0, 0, 0, time.UTC), 0,
+			[]string{"p202608"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := UpcomingCheckLogPartitions(tt.now, tt.ahead)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d partitions, want %d: %+v", len(got), len(tt.want), got)
+			}
+			for i, partition := range got {
+				if want := "payment_record_check_logs_" + tt.want[i]; partition.Name != want {
+					t.Errorf("partition[%d] = %s, want %s", i, partition.Name, want)
+				}
+				if i > 0 && !partition.From.Equal(got[i-1].To) {
+					t.Errorf("partition[%d] starts %s, previous ends %s (gap)", i, partition.From, got[i-1].To)
+				}
+			}
+		})
+	}
+}
+
+func TestParseCheckLogPartition(t *testing.T) {
+	tests := []struct {
+		name     string
+		table    string
+		wantOK   bool
+		wantFrom time.Time
+	}{
+		{"monthly partition", "payment_record_check_logs_p202602", true, time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"default partition", "payment_record_check_logs_default", false, time.Time{}},
+		{"other table", "payment_records", false, time.Time{}},
+		{"invalid month", "payment_record_check_logs_p202613", false, time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ParseCheckLogPartition(tt.table)
+			if ok != tt.wantOK {
+				t.Fatalf("ParseCheckLogPartition(%s) ok = %v, want %v", tt.table, ok, tt.wantOK)
+			}
+			if ok && (got.Name != tt.table || !got.From.Equal(tt.wantFrom)) {
+				t.Errorf("ParseCheckLogPartition(%s) = %+v", tt.table, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package entity
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestValidateTransition(t *testing.T) {
+	tests := []struct {
+		name    string
+		from    PaymentStatus
+		to      PaymentStatus
+		wantErr bool
+	}{
+		{name: "pending to paid", from: PaymentStatusPending, to: PaymentStatusPaid},
+		{name: "pending to cancelled", from: PaymentStatusPending, to: PaymentStatusCancelled},
+		{name: "pending to timed out", from: PaymentStatusPending, to: PaymentStatusTimedOut},
+		{name: "pending to not found", from: PaymentStatusPending, to: PaymentStatusNotFound},
+		{name: "timed out back to pending (re-check)", from: PaymentStatusTimedOut, to: PaymentStatusPending},
+		{name: "timed out finalized later", from: PaymentStatusTimedOut, to: PaymentStatusPaid},
+		{name: "timed out cancelled", from: PaymentStatusTimedOut, to: PaymentStatusCancelled},
+		{name: "empty status treated as pending", from: "", to: PaymentStatusPaid},
+		{name: "empty status to timed out", from: "", to: PaymentStatusTimedOut},
+		{name: "same status is a no-op", from: PaymentStatusPending, to: PaymentStatusPending},
+		{name: "same final status is a no-op", from: PaymentStatusPaid, to: PaymentStatusPaid},
+		{name: "paid is final", from: PaymentStatusPaid, to: PaymentStatusCancelled, wantErr: true},
+		{name: "paid cannot time out", from: PaymentStatusPaid, to: PaymentStatusTimedOut, wantErr: true},
+		{name: "cancelled is final", from: PaymentStatusCancelled, to: PaymentStatusPaid, wantErr: true},
+		{name: "cancelled cannot be re-checked", from: PaymentStatusCancelled, to: PaymentStatusPending, wantErr: true},
+		{name: "unpaid is final", from: PaymentStatusUnpaid, to: PaymentStatusPaid, wantErr: true},
+		{name: "failed is final", from: PaymentStatusFailed, to: PaymentStatusPending, wantErr: true},
+		{name: "expired is final", from: PaymentStatusExpired, to: PaymentStatusPaid, wantErr: true},
+		{name: "not found is final", from: PaymentStatusNotFound, to: PaymentStatusPaid, wantErr: true},
+		{name: "pending cannot go to empty", from: PaymentStatusPending, to: "", wantErr: true},
+		{name: "unknown source status", from: "REFUNDED", to: PaymentStatusPaid, wantErr: true},
+		{name: "unknown target status", from: PaymentStatusPending, to: "REFUNDED", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.from.ValidateTransition(tt.to)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateTransition(%q -> %q) error = %v, wantErr %v", tt.from, tt.to, err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidStatusTransition) {
+				t.Errorf("error = %v, want %v", err, ErrInvalidStatusTransition)
+			}
+			if got := tt.from.CanTransitionTo(tt.to); got == tt.wantErr {
+				t.Errorf("CanTransitionTo(%q -> %q) = %v, want %v", tt.from, tt.to, got, !tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPaymentStatusIsFinal(t *testing.T) {
+	tests := []struct {
+		status PaymentStatus
+		want   bool
+	}{
+		{status: PaymentStatusPending, want: false},
+		{status: PaymentStatusTimedOut, want: false},
+		{status: PaymentStatusPaid, want: true},
+		{status: PaymentStatusUnpaid, want: true},
+		{status: PaymentStatusFailed, want: true},
+		{status: PaymentStatusExpired, want: true},
+		{status: PaymentStatusCancelled, want: true},
+		{status: PaymentStatusNotFound, want: true},
+		{status: "", want: false},
+		{status: "REFUNDED", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.status), func(t *testing.T) {
+			if got := tt.status.IsFinal(); got != tt.want {
+				t.Errorf("IsFinal(%q) = %v, want %v", tt.status, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFinalPaymentStatuses(t *testing.T) {
+	want := []PaymentStatus{
+		PaymentStatusCancelled, PaymentStatusExpired, PaymentStatusFailed,
+		PaymentStatusNotFound, PaymentStatusPaid, PaymentStatusUnpaid,
+	}
+	if got := FinalPaymentStatuses(); !reflect.DeepEqual(got, want) {
+		t.Errorf("FinalPaymentStatuses() = %v, want %v", got, want)
+	}
+}
+
+func TestParsePaymentStatus(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    PaymentStatus
+		wantErr bool
+	}{
+		{raw: "PAID", want: PaymentStatusPaid},
+		{raw: "TIMED_OUT", want: PaymentStatusTimedOut},
+		{raw: "paid", wantErr: true},
+		{raw: "", wantErr: true},
+		{raw: "REFUNDED", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			got, err := ParsePaymentStatus(tt.raw)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePaymentStatus(%q) error = %v, wantErr %v", tt.raw, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParsePaymentStatus(%q) = %q, want %q", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package entity
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// Aksi kontrol polling yang disiarkan ke semua replica
+const (
+	PollingControlPauseAll  = "pause_all"
+	PollingControlResumeAll = "resume_all"
+	PollingControlPause     = "pause"
+	PollingControlResume    = "resume"
+	// Pause per provider (kredensial ditolak) sampai waktu tertentu; resume setelah probe berhasil
+	PollingControlPauseProvider  = "pause_provider"
+	PollingControlResumeProvider = "resume_provider"
+	// Boost diteruskan ke replica yang menjalankan task; hasilnya dibalas ke pengirim
+	PollingControlBoost       = "boost"
+	PollingControlBoostResult = "boost_result"
+	// Cancel disiarkan supaya replica pemilik task ikut melepas handle-nya
+	PollingControlCancel = "cancel"
+)
+
+// PollingControl pesan kontrol antar replica lewat Redis pub/sub.
+type PollingControl struct {
+	Action string    `json:"action"`
+	ID     uuid.UUID `json:"id"`     // task tujuan; uuid.Nil untuk aksi global
+	Origin string    `json:"origin"` // instance pengirim
+	// Khusus pause provider
+	Provider string    `json:"provider,omitempty"`
+	Until    time.Time `json:"until,omitempty"`
+	// Khusus boost: RequestID memasangkan balasan dengan permintaannya
+	RequestID string           `json:"request_id,omitempty"`
+	Task      *PollingTaskInfo `json:"task,omitempty"`
+	Error     string           `json:"error,omitempty"`
+}
+
+// PollingPauseState seluruh state pause di Redis: global, per task, dan per provider.
+type PollingPauseState struct {
+	All       bool
+	Tasks     map[uuid.UUID]bool
+	Providers map[string]time.Time // provider → akhir cooldown pause
+}
+
+// Paused apakah task (dengan provider-nya) sedang di-pause pada waktu now.
+func (s PollingPauseState) Paused(id uuid.UUID, provider string, now time.Time) bool {
+	return s.All || s.Tasks[id] || now.Before(s.Providers[provider])
+}
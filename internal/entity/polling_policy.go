@@ -0,0 +1,166 @@
+package entity
+
+import (
+	"beta-payment-api-client/internal/valueobject"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// PollingPolicy menentukan kurva backoff polling satu payment.
+type PollingPolicy struct {
+	InitialDelay    valueobject.Duration `json:"initial_delay"`
+	Multiplier      float64              `json:"multiplier"`
+	MaxDelay        valueobject.Duration `json:"max_delay"`
+	Jitter          float64              `json:"jitter"` // fraksi 0..1, mis. 0.2 = ±20%
+	BoostResetDelay valueobject.Duration `json:"boost_reset_delay"`
+	MaxAttempts     int                  `json:"max_attempts"` // 0 = tanpa batas
+	Timeout         valueobject.Duration `json:"timeout"`      // 0 = tanpa batas
+}
+
+// PollingPolicyOverride dipakai untuk override sebagian field (per tag / per request).
+// Field nil = pakai nilai dari policy dasar.
+type PollingPolicyOverride struct {
+	InitialDelay    *valueobject.Duration `json:"initial_delay,omitempty"`
+	Multiplier      *float64              `json:"multiplier,omitempty"`
+	MaxDelay        *valueobject.Duration `json:"max_delay,omitempty"`
+	Jitter          *float64              `json:"jitter,omitempty"`
+	BoostResetDelay *valueobject.Duration `json:"boost_reset_delay,omitempty"`
+	MaxAttempts     *int                  `json:"max_attempts,omitempty"`
+	Timeout         *valueobject.Duration `json:"timeout,omitempty"`
+}
+
+// PollingPolicySet berisi policy default + override per tag.
+type PollingPolicySet struct {
+	Default PollingPolicy
+	Tags    map[string]PollingPolicyOverride
+}
+
+func (p PollingPolicy) Validate() error {
+	if p.InitialDelay.Duration <= 0 {
+		return errors.New("initial_delay must be greater than 0")
+	}
+	if p.Multiplier < 1 {
+		return errors.New("multiplier must be at least 1")
+	}
+	if p.MaxDelay.Duration < p.InitialDelay.Duration {
+		return errors.New("max_delay must not be less than initial_delay")
+	}
+	if p.Jitter < 0 || p.Jitter > 1 {
+		return errors.New("jitter must be between 0 and 1")
+	}
+	if p.BoostResetDelay.Duration <= 0 {
+		return errors.New("boost_reset_delay must be greater than 0")
+	}
+	if p.MaxAttempts < 0 {
+		return errors.New("max_attempts must not be negative")
+	}
+	if p.Timeout.Duration < 0 {
+		return errors.New("timeout must not be negative")
+	}
+	return nil
+}
+
+// Validate hanya mengecek field yang di-set; kombinasi akhirnya dicek lagi lewat PollingPolicy.Validate.
+func (o PollingPolicyOverride) Validate() error {
+	if o.InitialDelay != nil && o.InitialDelay.Duration <= 0 {
+		return errors.New("initial_delay must be greater than 0")
+	}
+	if o.Multiplier != nil && *o.Multiplier < 1 {
+		return errors.New("multiplier must be at least 1")
+	}
+	if o.MaxDelay != nil && o.MaxDelay.Duration <= 0 {
+		return errors.New("max_delay must be greater than 0")
+	}
+	if o.InitialDelay != nil && o.MaxDelay != nil && o.MaxDelay.Duration < o.InitialDelay.Duration {
+		return errors.New("max_delay must not be less than initial_delay")
+	}
+	if o.Jitter != nil && (*o.Jitter < 0 || *o.Jitter > 1) {
+		return errors.New("jitter must be between 0 and 1")
+	}
+	if o.BoostResetDelay != nil && o.BoostResetDelay.Duration <= 0 {
+		return errors.New("boost_reset_delay must be greater than 0")
+	}
+	if o.MaxAttempts != nil && *o.MaxAttempts < 0 {
+		return errors.New("max_attempts must not be negative")
+	}
+	if o.Timeout != nil && o.Timeout.Duration < 0 {
+		return errors.New("timeout must not be negative")
+	}
+	return nil
+}
+
+// Apply mengembalikan salinan policy dengan field override yang di-set.
+func (p PollingPolicy) Apply(o *PollingPolicyOverride) PollingPolicy {
+	if o == nil {
+		return p
+	}
+	if o.InitialDelay != nil {
+		p.InitialDelay = *o.InitialDelay
+	}
+	if o.Multiplier != nil {
+		p.Multiplier = *o.Multiplier
+	}
+	if o.MaxDelay != nil {
+		p.MaxDelay = *o.MaxDelay
+	}
+	if o.Jitter != nil {
+		p.Jitter = *o.Jitter
+	}
+	if o.BoostResetDelay != nil {
+		p.BoostResetDelay = *o.BoostResetDelay
+	}
+	if o.MaxAttempts != nil {
+		p.MaxAttempts = *o.MaxAttempts
+	}
+	if o.Timeout != nil {
+		p.Timeout = *o.Timeout
+	}
+	return p
+}
+
+// Next menghitung delay berikutnya (exponential backoff dengan batas MaxDelay).
+func (p PollingPolicy) Next(current time.Duration) time.Duration {
+	next := time.Duration(float64(current) * p.Multiplier)
+	if next > p.MaxDelay.Duration {
+		next = p.MaxDelay.Duration
+	}
+	return next
+}
+
+// WithJitter mengacak delay sebesar ±Jitter supaya task tidak bangun bersamaan.
+func (p PollingPolicy) WithJitter(delay time.Duration) time.Duration {
+	if p.Jitter <= 0 {
+		return delay
+	}
+	spread := float64(delay) * p.Jitter
+	jittered := time.Duration(float64(delay) + (rand.Float64()*2-1)*spread)
+	if jittered < 0 {
+		return 0
+	}
+	return jittered
+}
+
+// Resolve memilih policy untuk sebuah tag, lalu menerapkan override dari request (jika ada).
+func (s PollingPolicySet) Resolve(tag string, override *PollingPolicyOverride) PollingPolicy {
+	policy := s.Default
+	if tagOverride, ok := s.Tags[tag]; ok {
+		policy = policy.Apply(&tagOverride)
+	}
+	return policy.Apply(override)
+}
+
+// ParsePollingPolicyOverrides membaca override per tag dari JSON, mis.
+// {"qris":{"initial_delay":"2s","max_delay":"20s"}}
+func ParsePollingPolicyOverrides(raw string) (map[string]PollingPolicyOverride, error) {
+	overrides := map[string]PollingPolicyOverride{}
+	if raw == "" {
+		return overrides, nil
+	}
+	if err := json.Unmarshal([]byte(raw), &overrides); err != nil {
+		return nil, fmt.Errorf("invalid polling tag policies: %w", err)
+	}
+	return overrides, nil
+}
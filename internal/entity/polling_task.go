@@ -0,0 +1,72 @@
+package entity
+
+import (
+	"beta-payment-api-client/internal/valueobject"
+	"github.com/google/uuid"
+	"time"
+)
+
+// PollingTask adalah state task polling yang dipersist di Redis supaya bisa di-restore.
+type PollingTask struct {
+	ID            uuid.UUID            `json:"id"`
+	Tag           string               `json:"tag"`
+	Provider      string               `json:"provider,omitempty"`
+	Policy        PollingPolicy        `json:"policy"`
+	StartedAt     time.Time            `json:"started_at"`
+	Attempts      int                  `json:"attempts"`
+	Delay         valueobject.Duration `json:"delay"`
+	LastStatus    string               `json:"last_status,omitempty"` // status kanonik
+	LastRawStatus string               `json:"last_raw_status,omitempty"`
+	LastError     string               `json:"last_error,omitempty"`
+	NotFoundCount int                  `json:"not_found_count,omitempty"` // 404 berturut-turut dari payment server
+	LastCheckedAt *time.Time           `json:"last_checked_at,omitempty"`
+}
+
+// PollingTaskInfo adalah tampilan task untuk API: state Redis + jadwal, pemilik, dan status pause.
+type PollingTaskInfo struct {
+	PollingTask
+	NextRetryAt *time.Time `json:"next_retry_at"`
+	Owner       string     `json:"owner"`
+	Paused      bool       `json:"paused"`
+}
+
+// PollingTaskFilter filter + paginasi untuk list task.
+type PollingTaskFilter struct {
+	Status  string
+	MinAge  time.Duration
+	MaxAge  time.Duration
+	Page    int
+	PerPage int
+}
+
+// Deadline mengembalikan batas waktu task; zero time = tanpa batas.
+func (t PollingTask) Deadline() time.Time {
+	if t.Policy.Timeout.Duration <= 0 {
+		return time.Time{}
+	}
+	return t.StartedAt.Add(t.Policy.Timeout.Duration)
+}
+
+// Exhausted true jika task sudah melewati batas attempt atau deadline.
+func (t PollingTask) Exhausted(now time.Time) bool {
+	if t.Policy.MaxAttempts > 0 && t.Attempts >= t.Policy.MaxAttempts {
+		return true
+	}
+	deadline := t.Deadline()
+	return !deadline.IsZero() && !now.Before(deadline)
+}
+
+// Match true jika task lolos filter status (last status) dan umur (sejak StartedAt).
+func (f PollingTaskFilter) Match(t PollingTask, now time.Time) bool {
+	if f.Status != "" && t.LastStatus != f.Status {
+		return false
+	}
+	age := now.Sub(t.StartedAt)
+	if f.MinAge > 0 && age < f.MinAge {
+		return false
+	}
+	if f.MaxAge > 0 && age > f.MaxAge {
+		return false
+	}
+	return true
+}
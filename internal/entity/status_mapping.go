@@ -0,0 +1,86 @@
+package entity
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// StatusMappingRule status kanonik untuk satu status mentah provider.
+// Terminal = payment dianggap selesai: status disimpan ke record dan polling berhenti.
+type StatusMappingRule struct {
+	Status   PaymentStatus `json:"status"`
+	Terminal bool          `json:"terminal"`
+}
+
+// StatusMapping status mentah provider (case-insensitive) → status kanonik.
+type StatusMapping map[string]StatusMappingRule
+
+// StatusMappings mapping per provider; provider tanpa mapping memakai Default.
+type StatusMappings struct {
+	Default   StatusMapping
+	Providers map[string]StatusMapping
+}
+
+// DefaultStatusMapping mapping identitas untuk status kanonik yang bisa dikirim provider;
+// status final (PAID, UNPAID, FAILED, EXPIRED, CANCELLED) terminal.
+func DefaultStatusMapping() StatusMapping {
+	mapping := StatusMapping{}
+	for _, status := range []PaymentStatus{
+		PaymentStatusPending, PaymentStatusPaid, PaymentStatusUnpaid,
+		PaymentStatusFailed, PaymentStatusExpired, PaymentStatusCancelled,
+	} {
+		mapping[string(status)] = StatusMappingRule{Status: status, Terminal: status.IsFinal()}
+	}
+	return mapping
+}
+
+func (r StatusMappingRule) Validate() error {
+	if !r.Status.IsValid() {
+		return fmt.Errorf("unknown canonical status %q", r.Status)
+	}
+	if r.Terminal && !r.Status.IsFinal() {
+		return fmt.Errorf("terminal mapping must use a final status, got %s", r.Status)
+	}
+	return nil
+}
+
+// Map mencari rule untuk status mentah; ok=false jika status tidak dikenal.
+func (m StatusMapping) Map(raw string) (StatusMappingRule, bool) {
+	rule, ok := m[strings.ToUpper(strings.TrimSpace(raw))]
+	return rule, ok
+}
+
+// Map memilih mapping provider (atau Default) lalu memetakan status mentah.
+func (s StatusMappings) Map(provider, raw string) (StatusMappingRule, bool) {
+	mapping, ok := s.Providers[provider]
+	if !ok {
+		mapping = s.Default
+	}
+	return mapping.Map(raw)
+}
+
+// ParseStatusMappings membaca mapping per provider dari JSON, mis.
+// {"acme":{"SETTLED":{"status":"PAID","terminal":true},"AUTHORIZED":{"status":"PENDING"}}}
+func ParseStatusMappings(raw string) (map[string]StatusMapping, error) {
+	parsed := map[string]StatusMapping{}
+	if raw == "" {
+		return parsed, nil
+	}
+	if err := json.Unmarshal([]byte(raw), &parsed); err != nil {
+		return nil, fmt.Errorf("invalid status mappings: %w", err)
+	}
+
+	mappings := make(map[string]StatusMapping, len(parsed))
+	for provider, mapping := range parsed {
+		normalized := make(StatusMapping, len(mapping))
+		for rawStatus, rule := range mapping {
+			if err := rule.Validate(); err != nil {
+				return nil, fmt.Errorf("invalid status mappings: %s.%s: %w", provider, rawStatus, err)
+			}
+			normalized[strings.ToUpper(strings.TrimSpace(rawStatus))] = rule
+		}
+		mappings[provider] = normalized
+	}
+	return mappings, nil
+}
@@ -0,0 +1,86 @@
+package entity
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStatusMappingsMap(t *testing.T) {
+	mappings := StatusMappings{
+		Default: DefaultStatusMapping(),
+		Providers: map[string]StatusMapping{
+			"acme": {
+				"SETTLED":    {Status: PaymentStatusPaid, Terminal: true},
+				"AUTHORIZED": {Status: PaymentStatusPending},
+			},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		provider  string
+		raw       string
+		want      StatusMappingRule
+		wantKnown bool
+	}{
+		{name: "default pending is non-terminal", provider: "payment_server", raw: "PENDING", want: StatusMappingRule{Status: PaymentStatusPending}, wantKnown: true},
+		{name: "default paid is terminal", provider: "payment_server", raw: "PAID", want: StatusMappingRule{Status: PaymentStatusPaid, Terminal: true}, wantKnown: true},
+		{name: "default is case-insensitive and trimmed", provider: "payment_server", raw: "  cancelled ", want: StatusMappingRule{Status: PaymentStatusCancelled, Terminal: true}, wantKnown: true},
+		{name: "default does not map internal statuses", provider: "payment_server", raw: "TIMED_OUT"},
+		{name: "default unknown status", provider: "payment_server", raw: "SETTLED"},
+		{name: "provider terminal mapping", provider: "acme", raw: "settled", want: StatusMappingRule{Status: PaymentStatusPaid, Terminal: true}, wantKnown: true},
+		{name: "provider non-terminal mapping", provider: "acme", raw: "AUTHORIZED", want: StatusMappingRule{Status: PaymentStatusPending}, wantKnown: true},
+		{name: "provider mapping replaces default", provider: "acme", raw: "PAID"},
+		{name: "provider without mapping uses default", provider: "other", raw: "EXPIRED", want: StatusMappingRule{Status: PaymentStatusExpired, Terminal: true}, wantKnown: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, known := mappings.Map(tt.provider, tt.raw)
+			if known != tt.wantKnown {
+				t.Fatalf("Map(%q, %q) known = %v, want %v", tt.provider, tt.raw, known, tt.wantKnown)
+			}
+			if got != tt.want {
+				t.Errorf("Map(%q, %q) = %+v, want %+v", tt.provider, tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseStatusMappings(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		want    map[string]StatusMapping
+		wantErr bool
+	}{
+		{name: "empty", raw: "", want: map[string]StatusMapping{}},
+		{
+			name: "raw statuses are normalized",
+			raw:  `{"acme":{" settled ":{"status":"PAID","terminal":true},"authorized":{"status":"PENDING"}}}`,
+			want: map[string]StatusMapping{"acme": {
+				"SETTLED":    {Status: PaymentStatusPaid, Terminal: true},
+				"AUTHORIZED": {Status: PaymentStatusPending},
+			}},
+		},
+		{name: "invalid json", raw: `{"acme":`, wantErr: true},
+		{name: "unknown canonical status", raw: `{"acme":{"SETTLED":{"status":"DONE"}}}`, wantErr: true},
+		{name: "terminal rule with non-final status", raw: `{"acme":{"WAITING":{"status":"PENDING","terminal":true}}}`, wantErr: true},
+		{name: "terminal rule with timed out", raw: `{"acme":{"STALE":{"status":"TIMED_OUT","terminal":true}}}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseStatusMappings(tt.raw)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseStatusMappings() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseStatusMappings() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
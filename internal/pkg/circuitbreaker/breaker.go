@@ -0,0 +1,181 @@
+package circuitbreaker
+
+import (
+	"errors"
+	"github.com/rs/zerolog"
+	"sync"
+	"time"
+)
+
+type State string
+
+const (
+	StateClosed   State = "closed"
+	StateOpen     State = "open"
+	StateHalfOpen State = "half-open"
+)
+
+var ErrOpen = errors.New("circuit breaker is open")
+
+// OpenError dikembalikan selama breaker open; RetryAfter = sisa waktu sampai breaker boleh dicoba lagi.
+type OpenError struct {
+	RetryAfter time.Duration
+}
+
+func (e *OpenError) Error() string { return ErrOpen.Error() }
+func (e *OpenError) Is(target error) bool {
+	return target == ErrOpen
+}
+
+// RetryAfter mengambil sisa waktu open dari err; 0 jika err bukan OpenError.
+func RetryAfter(err error) time.Duration {
+	var openErr *OpenError
+	if errors.As(err, &openErr) {
+		return openErr.RetryAfter
+	}
+	return 0
+}
+
+type Config struct {
+	FailureThreshold int           // kegagalan berturut-turut sebelum open
+	OpenTimeout      time.Duration // lama open sebelum half-open
+	HalfOpenRequests int           // jumlah probe saat half-open; semua harus sukses untuk kembali closed
+}
+
+// Snapshot adalah state breaker untuk endpoint health.
+type Snapshot struct {
+	State               State      `json:"state"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	OpenedAt            *time.Time `json:"opened_at,omitempty"`
+	RetryAt             *time.Time `json:"retry_at,omitempty"`
+}
+
+// Breaker circuit breaker closed → open → half-open → closed.
+// Setiap Allow yang sukses harus diikuti tepat satu Done dengan generation yang sama;
+// hasil dari generation lama (sebelum state berubah) diabaikan.
+type Breaker struct {
+	name   string
+	cfg    Config
+	logger zerolog.Logger
+
+	mu         sync.Mutex
+	state      State
+	generation uint64
+	failures   int
+	openedAt   time.Time
+	probes     int // probe half-open yang sedang jalan
+	successes  int // probe half-open yang sukses
+}
+
+func NewBreaker(name string, cfg Config, logger zerolog.Logger) *Breaker {
+	if cfg.FailureThreshold < 1 {
+		cfg.FailureThreshold = 1
+	}
+	if cfg.HalfOpenRequests < 1 {
+		cfg.HalfOpenRequests = 1
+	}
+	return &Breaker{name: name, cfg: cfg, logger: logger, state: StateClosed}
+}
+
+// Allow mengecek apakah request boleh jalan. Return *OpenError jika breaker open
+// (atau kuota probe half-open sudah habis).
+func (b *Breaker) Allow() (uint64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	if b.state == StateOpen {
+		retryAt := b.openedAt.Add(b.cfg.OpenTimeout)
+		if now.Before(retryAt) {
+			return b.generation, &OpenError{RetryAfter: retryAt.Sub(now)}
+		}
+		b.setState(StateHalfOpen)
+	}
+	if b.state == StateHalfOpen {
+		if b.probes >= b.cfg.HalfOpenRequests {
+			return b.generation, &OpenError{RetryAfter: b.cfg.OpenTimeout}
+		}
+		b.probes++
+	}
+	return b.generation, nil
+}
+
+// Done mencatat hasil request yang diizinkan Allow.
+func (b *Breaker) Done(generation uint64, success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if generation != b.generation {
+		return
+	}
+
+	switch b.state {
+	case StateClosed:
+		if success {
+			b.failures = 0
+			return
+		}
+		b.failures++
+		if b.failures >= b.cfg.FailureThreshold {
+			b.setState(StateOpen)
+		}
+	case StateHalfOpen:
+		if !success {
+			b.failures++
+			b.setState(StateOpen)
+			return
+		}
+		b.successes++
+		if b.successes >= b.cfg.HalfOpenRequests {
+			b.setState(StateClosed)
+		}
+	}
+}
+
+// Release mengembalikan slot tanpa mencatat hasil (mis. request dibatalkan pemanggil).
+func (b *Breaker) Release(generation uint64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if generation == b.generation && b.state == StateHalfOpen && b.probes > 0 {
+		b.probes--
+	}
+}
+
+func (b *Breaker) State() State {
+	return b.Snapshot().State
+}
+
+func (b *Breaker) Snapshot() Snapshot {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	snapshot := Snapshot{State: b.state, ConsecutiveFailures: b.failures}
+	if b.state != StateClosed {
+		openedAt := b.openedAt
+		retryAt := openedAt.Add(b.cfg.OpenTimeout)
+		snapshot.OpenedAt = &openedAt
+		snapshot.RetryAt = &retryAt
+	}
+	return snapshot
+}
+
+// setState harus dipanggil dengan mu terkunci.
+func (b *Breaker) setState(state State) {
+	prev := b.state
+	b.state = state
+	b.generation++
+	b.probes = 0
+	b.successes = 0
+
+	switch state {
+	case StateOpen:
+		b.openedAt = time.Now()
+		b.logger.Warn().Msgf("🔌 Circuit breaker %s: %s -> %s after %d consecutive failures (retry in %s)", b.name, prev, state, b.failures, b.cfg.OpenTimeout)
+	case StateHalfOpen:
+		b.logger.Info().Msgf("🔌 Circuit breaker %s: %s -> %s", b.name, prev, state)
+	case StateClosed:
+		b.failures = 0
+		b.logger.Info().Msgf("🔌 Circuit breaker %s: %s -> %s", b.name, prev, state)
+	}
+}
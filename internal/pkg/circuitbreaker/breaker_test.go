@@ -0,0 +1,196 @@
+package circuitbreaker
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+const testOpenTimeout = 20 * time.Millisecond
+
+// Langkah uji state machine breaker
+const (
+	opAllow     = "allow"      // Allow; wantErr = ditolak (ErrOpen)
+	opSuccess   = "success"    // Done(sukses) untuk izin terakhir
+	opFailure   = "failure"    // Done(gagal) untuk izin terakhir
+	opRelease   = "release"    // Release untuk izin terakhir
+	opStaleDone = "stale_done" // Done(sukses) untuk izin pertama (generation lama)
+	opWait      = "wait"       // tunggu sampai OpenTimeout lewat
+)
+
+type step struct {
+	op        string
+	wantErr   bool
+	wantState State
+}
+
+func TestBreakerStateMachine(t *testing.T) {
+	tests := []struct {
+		name  string
+		cfg   Config
+		steps []step
+	}{
+		{
+			name: "opens after consecutive failures",
+			cfg:  Config{FailureThreshold: 2, OpenTimeout: time.Hour, HalfOpenRequests: 1},
+			steps: []step{
+				{op: opAllow, wantState: StateClosed},
+				{op: opFailure, wantState: StateClosed},
+				{op: opAllow, wantState: StateClosed},
+				{op: opFailure, wantState: StateOpen},
+				{op: opAllow, wantErr: true, wantState: StateOpen},
+			},
+		},
+		{
+			name: "success resets failure count",
+			cfg:  Config{FailureThreshold: 2, OpenTimeout: time.Hour, HalfOpenRequests: 1},
+			steps: []step{
+				{op: opAllow, wantState: StateClosed},
+				{op: opFailure, wantState: StateClosed},
+				{op: opAllow, wantState: StateClosed},
+				{op: opSuccess, wantState: StateClosed},
+				{op: opAllow, wantState: StateClosed},
+				{op: opFailure, wantState: StateClosed},
+			},
+		},
+		{
+			name: "half-open closes after all probes succeed",
+			cfg:  Config{FailureThreshold: 1, OpenTimeout: testOpenTimeout, HalfOpenRequests: 2},
+			steps: []step{
+				{op: opAllow, wantState: StateClosed},
+				{op: opFailure, wantState: StateOpen},
+				{op: opWait, wantState: StateOpen},
+				{op: opAllow, wantState: StateHalfOpen},
+				{op: opAllow, wantState: StateHalfOpen},
+				{op: opAllow, wantErr: true, wantState: StateHalfOpen}, // kuota probe habis
+				{op: opSuccess, wantState: StateHalfOpen},
+				{op: opSuccess, wantState: StateClosed},
+				{op: opAllow, wantState: StateClosed},
+			},
+		},
+		{
+			name: "failed probe reopens",
+			cfg:  Config{FailureThreshold: 1, OpenTimeout: testOpenTimeout, HalfOpenRequests: 2},
+			steps: []step{
+				{op: opAllow, wantState: StateClosed},
+				{op: opFailure, wantState: StateOpen},
+				{op: opWait, wantState: StateOpen},
+				{op: opAllow, wantState: StateHalfOpen},
+				{op: opFailure, wantState: StateOpen},
+				{op: opAllow, wantErr: true, wantState: StateOpen},
+			},
+		},
+		{
+			name: "released probe frees its half-open slot",
+			cfg:  Config{FailureThreshold: 1, OpenTimeout: testOpenTimeout, HalfOpenRequests: 1},
+			steps: []step{
+				{op: opAllow, wantState: StateClosed},
+				{op: opFailure, wantState: StateOpen},
+				{op: opWait, wantState: StateOpen},
+				{op: opAllow, wantState: StateHalfOpen},
+				{op: opAllow, wantErr: true, wantState: StateHalfOpen},
+				{op: opRelease, wantState: StateHalfOpen},
+				{op: opAllow, wantState: StateHalfOpen},
+				{op: opSuccess, wantState: StateClosed},
+			},
+		},
+		{
+			name: "result from previous generation is ignored",
+			cfg:  Config{FailureThreshold: 1, OpenTimeout: testOpenTimeout, HalfOpenRequests: 1},
+			steps: []step{
+				{op: opAllow, wantState: StateClosed}, // izin lama, hasilnya datang terlambat
+				{op: opAllow, wantState: StateClosed},
+				{op: opFailure, wantState: StateOpen},
+				{op: opWait, wantState: StateOpen},
+				{op: opAllow, wantState: StateHalfOpen},
+				{op: opStaleDone, wantState: StateHalfOpen},
+				{op: opSuccess, wantState: StateClosed},
+			},
+		},
+		{
+			name: "invalid config falls back to one failure and one probe",
+			cfg:  Config{OpenTimeout: testOpenTimeout},
+			steps: []step{
+				{op: opAllow, wantState: StateClosed},
+				{op: opFailure, wantState: StateOpen},
+				{op: opWait, wantState: StateOpen},
+				{op: opAllow, wantState: StateHalfOpen},
+				{op: opAllow, wantErr: true, wantState: StateHalfOpen},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewBreaker("test", tt.cfg, zerolog.Nop())
+			var generations []uint64
+
+			for i, s := range tt.steps {
+				switch s.op {
+				case opAllow:
+					generation, err := b.Allow()
+					if (err != nil) != s.wantErr {
+						t.Fatalf("step %d: Allow error = %v, wantErr %v", i, err, s.wantErr)
+					}
+					if err != nil && !errors.Is(err, ErrOpen) {
+						t.Fatalf("step %d: Allow error = %v, want %v", i, err, ErrOpen)
+					}
+					if err == nil {
+						generations = append(generations, generation)
+					}
+				case opSuccess:
+					b.Done(generations[len(generations)-1], true)
+				case opFailure:
+					b.Done(generations[len(generations)-1], false)
+				case opRelease:
+					b.Release(generations[len(generations)-1])
+				case opStaleDone:
+					b.Done(generations[0], true)
+				case opWait:
+					time.Sleep(tt.cfg.OpenTimeout + 10*time.Millisecond)
+				}
+				if got := b.State(); got != s.wantState {
+					t.Fatalf("step %d (%s): state = %s, want %s", i, s.op, got, s.wantState)
+				}
+			}
+		})
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want time.Duration
+	}{
+		{name: "nil", err: nil, want: 0},
+		{name: "open error", err: &OpenError{RetryAfter: time.Minute}, want: time.Minute},
+		{name: "wrapped open error", err: fmt.Errorf("fetch: %w", &OpenError{RetryAfter: time.Second}), want: time.Second},
+		{name: "other error", err: errors.New("boom"), want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RetryAfter(tt.err); got != tt.want {
+				t.Errorf("RetryAfter() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpenErrorRetryAfterCountsDown(t *testing.T) {
+	b := NewBreaker("test", Config{FailureThreshold: 1, OpenTimeout: time.Minute, HalfOpenRequests: 1}, zerolog.Nop())
+	generation, _ := b.Allow()
+	b.Done(generation, false)
+
+	_, err := b.Allow()
+	if retryAfter := RetryAfter(err); retryAfter <= 0 || retryAfter > time.Minute {
+		t.Errorf("RetryAfter = %s, want within (0, %s]", retryAfter, time.Minute)
+	}
+	if snapshot := b.Snapshot(); snapshot.OpenedAt == nil || snapshot.RetryAt == nil || snapshot.ConsecutiveFailures != 1 {
+		t.Errorf("snapshot = %+v, want opened_at, retry_at and 1 failure", snapshot)
+	}
+}
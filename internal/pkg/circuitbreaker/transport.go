@@ -0,0 +1,37 @@
+package circuitbreaker
+
+import (
+	"net/http"
+)
+
+// Transport adalah http.RoundTripper yang menolak request saat breaker open.
+// Error jaringan, 5xx, dan 429 dihitung sebagai kegagalan.
+type Transport struct {
+	Base    http.RoundTripper
+	Breaker *Breaker
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	generation, err := t.Breaker.Allow()
+	if err != nil {
+		return nil, err
+	}
+
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		// Dibatalkan pemanggil (timeout / shutdown) → bukan tanda server bermasalah
+		if req.Context().Err() != nil {
+			t.Breaker.Release(generation)
+			return nil, err
+		}
+		t.Breaker.Done(generation, false)
+		return nil, err
+	}
+
+	t.Breaker.Done(generation, resp.StatusCode < http.StatusInternalServerError && resp.StatusCode != http.StatusTooManyRequests)
+	return resp, nil
+}
@@ -0,0 +1,151 @@
+package healthcheck
+
+import (
+	"context"
+	"github.com/rs/zerolog"
+	"sync"
+	"time"
+)
+
+const (
+	StatusUp      = "up"
+	StatusDown    = "down"
+	StatusPending = "pending" // belum pernah dicek
+
+	DefaultInterval = 10 * time.Second
+	DefaultTimeout  = 3 * time.Second
+)
+
+// CheckFunc mengecek satu dependency; nil = sehat.
+type CheckFunc func(ctx context.Context) error
+
+// DependencyStatus hasil cek terakhir satu dependency.
+type DependencyStatus struct {
+	Name      string     `json:"name"`
+	Status    string     `json:"status"`
+	LatencyMs float64    `json:"latency_ms"`
+	Error     string     `json:"error,omitempty"`
+	CheckedAt *time.Time `json:"checked_at,omitempty"`
+}
+
+type check struct {
+	name string
+	fn   CheckFunc
+}
+
+// Checker menjalankan semua cek dependency secara berkala di background;
+// endpoint readiness cukup membaca hasil terakhir (tidak memanggil dependency per request).
+type Checker struct {
+	checks   []check
+	interval time.Duration
+	timeout  time.Duration
+	logger   zerolog.Logger
+
+	mu      sync.RWMutex
+	results map[string]DependencyStatus
+}
+
+// NewChecker: interval / timeout <= 0 (mis. salah konfigurasi) → pakai default; time.NewTicker panic untuk interval <= 0.
+func NewChecker(interval, timeout time.Duration, logger zerolog.Logger) *Checker {
+	if interval <= 0 {
+		logger.Warn().Msgf("‼️ Invalid readiness check interval %s, using default %s", interval, DefaultInterval)
+		interval = DefaultInterval
+	}
+	if timeout <= 0 {
+		logger.Warn().Msgf("‼️ Invalid readiness check timeout %s, using default %s", timeout, DefaultTimeout)
+		timeout = DefaultTimeout
+	}
+	return &Checker{
+		interval: interval,
+		timeout:  timeout,
+		logger:   logger,
+		results:  map[string]DependencyStatus{},
+	}
+}
+
+// Register menambahkan dependency; panggil sebelum Start.
+func (c *Checker) Register(name string, fn CheckFunc) {
+	c.checks = append(c.checks, check{name: name, fn: fn})
+	c.results[name] = DependencyStatus{Name: name, Status: StatusPending}
+}
+
+// Start langsung menjalankan semua cek sekali, lalu mengulang tiap interval sampai ctx selesai.
+func (c *Checker) Start(ctx context.Context) {
+	c.runAll(ctx)
+	go func() {
+		ticker := time.NewTicker(c.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				c.runAll(ctx)
+			}
+		}
+	}()
+}
+
+// Ready true jika semua dependency up; status dikembalikan sesuai urutan Register.
+func (c *Checker) Ready() (bool, []DependencyStatus) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	ready := true
+	statuses := make([]DependencyStatus, 0, len(c.checks))
+	for _, chk := range c.checks {
+		status := c.results[chk.name]
+		if status.Status != StatusUp {
+			ready = false
+		}
+		statuses = append(statuses, status)
+	}
+	return ready, statuses
+}
+
+// runAll menjalankan semua cek paralel, masing-masing dengan timeout sendiri.
+func (c *Checker) runAll(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, chk := range c.checks {
+		wg.Add(1)
+		go func(chk check) {
+			defer wg.Done()
+			c.run(ctx, chk)
+		}(chk)
+	}
+	wg.Wait()
+}
+
+func (c *Checker) run(ctx context.Context, chk check) {
+	checkCtx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := chk.fn(checkCtx)
+	checkedAt := time.Now()
+
+	result := DependencyStatus{
+		Name:      chk.name,
+		Status:    StatusUp,
+		LatencyMs: float64(checkedAt.Sub(start).Microseconds()) / 1000,
+		CheckedAt: &checkedAt,
+	}
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+
+	c.mu.Lock()
+	prev := c.results[chk.name]
+	c.results[chk.name] = result
+	c.mu.Unlock()
+
+	// Log hanya saat status berubah supaya tidak spam tiap interval
+	if prev.Status != result.Status {
+		if err != nil {
+			c.logger.Warn().Err(err).Msgf("🩺 Dependency %s is %s", chk.name, result.Status)
+		} else {
+			c.logger.Info().Msgf("🩺 Dependency %s is %s", chk.name, result.Status)
+		}
+	}
+}
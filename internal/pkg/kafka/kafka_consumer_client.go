@@ -45,3 +45,10 @@ func (k *KafkaConsumerClient) InitKafkaConsumer() *KafkaConsumerClient {
 	k.Reader = reader
 	return k
 }
+
+func (k *KafkaConsumerClient) Close() error {
+	if k.Reader == nil {
+		return nil
+	}
+	return k.Reader.Close()
+}
@@ -2,6 +2,8 @@ package kafka
 
 import (
 	"beta-payment-api-client/config"
+	"context"
+	"errors"
 	"fmt"
 	"github.com/rs/zerolog"
 	"github.com/segmentio/kafka-go"
@@ -11,7 +13,9 @@ type KafkaProducerClient struct {
 	kafkaHost                string
 	kafkaPort                string
 	kafkaTopicPaymentSuccess string
+	kafkaTopicPaymentTimeout string
 	Writer                   *kafka.Writer
+	TimeoutWriter            *kafka.Writer
 	logger                   zerolog.Logger
 }
 
@@ -20,6 +24,7 @@ func NewKafkaProducerClient(cfg *config.AppConfig, logger zerolog.Logger) *Kafka
 		kafkaHost:                cfg.KafkaHost,
 		kafkaPort:                cfg.KafkaPort,
 		kafkaTopicPaymentSuccess: cfg.KafkaTopicPaymentSuccess,
+		kafkaTopicPaymentTimeout: cfg.KafkaTopicPaymentTimeout,
 		logger:                   logger,
 	}
 }
@@ -32,5 +37,37 @@ func (k *KafkaProducerClient) InitKafkaProducer() *KafkaProducerClient {
 		RequiredAcks: kafka.RequireAll,
 	}
 	k.Writer = writer
+
+	// Topic terpisah untuk payment yang berhenti di-poll karena timeout/attempt habis
+	k.TimeoutWriter = &kafka.Writer{
+		Addr:         kafka.TCP(fmt.Sprintf("%s:%s", k.kafkaHost, k.kafkaPort)),
+		Topic:        k.kafkaTopicPaymentTimeout,
+		Balancer:     &kafka.LeastBytes{},
+		RequiredAcks: kafka.RequireAll,
+	}
 	return k
 }
+
+func (k *KafkaProducerClient) Close() error {
+	var errs []error
+	for _, writer := range []*kafka.Writer{k.Writer, k.TimeoutWriter} {
+		if writer == nil {
+			continue
+		}
+		if err := writer.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Ping memastikan broker Kafka bisa dihubungi (untuk readiness check).
+func (k *KafkaProducerClient) Ping(ctx context.Context) error {
+	conn, err := kafka.DialContext(ctx, "tcp", fmt.Sprintf("%s:%s", k.kafkaHost, k.kafkaPort))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	_, err = conn.Brokers()
+	return err
+}
@@ -2,6 +2,7 @@ package logger
 
 import (
 	"beta-payment-api-client/config"
+	"beta-payment-api-client/internal/pkg/redact"
 	"bytes"
 	"github.com/rs/zerolog"
 	"io"
@@ -24,7 +25,8 @@ func NewTelemetryClient(apiKey string, endpoint string) *TelemetryClient {
 	}
 }
 
-func InitLoggerWithTelemetry(cfg *config.AppConfig) zerolog.Logger {
+// InitLoggerWithTelemetry membuat logger console (+ telemetry kalau aktif); setiap baris diredaksi sebelum keluar.
+func InitLoggerWithTelemetry(cfg *config.AppConfig, redactor *redact.Redactor) zerolog.Logger {
 	consoleWriter := zerolog.ConsoleWriter{Out: os.Stdout, TimeFormat: time.RFC3339}
 
 	var writer io.Writer = consoleWriter
@@ -38,7 +40,7 @@ func InitLoggerWithTelemetry(cfg *config.AppConfig) zerolog.Logger {
 		writer = zerolog.MultiLevelWriter(consoleWriter, telemetryWriter)
 	}
 
-	return zerolog.New(writer).With().Timestamp().Logger()
+	return zerolog.New(redact.NewWriter(redactor, writer)).With().Timestamp().Logger()
 }
 
 func (t *TelemetryClient) Write(p []byte) (n int, err error) {
@@ -0,0 +1,15 @@
+package metrics
+
+import (
+	"expvar"
+)
+
+// Counter proses, diekspos lewat expvar di /debug/vars.
+var (
+	// UnknownPaymentStatuses jumlah status mentah yang tidak ada di mapping, key "provider:STATUS".
+	UnknownPaymentStatuses = expvar.NewMap("unknown_payment_statuses")
+
+	// CheckLogWriter counter writer check log asynchronous: enqueued, blocked, dropped, written, failed, batches,
+	// plus gauge buffered / capacity.
+	CheckLogWriter = expvar.NewMap("check_log_writer")
+)
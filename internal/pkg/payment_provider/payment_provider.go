@@ -0,0 +1,90 @@
+package payment_provider
+
+import (
+	"beta-payment-api-client/internal/entity"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"net/http"
+	"sort"
+)
+
+var ErrUnknownProvider = errors.New("unknown payment provider")
+
+// PaymentProvider adapter satu PSP. FetchStatus mengembalikan status mentah dari provider
+// beserta request/response HTTP untuk check log. Replay mengirim ulang request yang tercatat di check log
+// dengan kredensial baru dari provider.
+type PaymentProvider interface {
+	Name() string
+	FetchStatus(ctx context.Context, id uuid.UUID) (string, *entity.PaymentRecordCheckHTTP, error)
+	Replay(ctx context.Context, method, rawURL string, header http.Header, body []byte) (*entity.PaymentRecordCheckHTTP, error)
+	Health(ctx context.Context) error
+}
+
+// Registry daftar provider + routing tag → provider.
+// Payment tanpa provider eksplisit diarahkan lewat tag-nya, lalu ke provider default.
+type Registry struct {
+	providers       map[string]PaymentProvider
+	tags            map[string]string
+	defaultProvider string
+}
+
+func NewRegistry(defaultProvider PaymentProvider) *Registry {
+	return &Registry{
+		providers:       map[string]PaymentProvider{defaultProvider.Name(): defaultProvider},
+		tags:            map[string]string{},
+		defaultProvider: defaultProvider.Name(),
+	}
+}
+
+func (r *Registry) Register(provider PaymentProvider) {
+	r.providers[provider.Name()] = provider
+}
+
+// RouteTag mengarahkan payment dengan tag tertentu ke provider; provider harus sudah terdaftar.
+func (r *Registry) RouteTag(tag, provider string) error {
+	if _, ok := r.providers[provider]; !ok {
+		return fmt.Errorf("%w: %s (tag %s)", ErrUnknownProvider, provider, tag)
+	}
+	r.tags[tag] = provider
+	return nil
+}
+
+func (r *Registry) Get(name string) (PaymentProvider, error) {
+	provider, ok := r.providers[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownProvider, name)
+	}
+	return provider, nil
+}
+
+// Resolve menentukan nama provider untuk sebuah payment: provider eksplisit (harus terdaftar),
+// lalu routing tag, lalu provider default.
+func (r *Registry) Resolve(provider, tag string) (string, error) {
+	if provider != "" {
+		if _, ok := r.providers[provider]; !ok {
+			return "", fmt.Errorf("%w: %s", ErrUnknownProvider, provider)
+		}
+		return provider, nil
+	}
+	if routed, ok := r.tags[tag]; ok {
+		return routed, nil
+	}
+	return r.defaultProvider, nil
+}
+
+// Providers semua provider terdaftar, urut nama.
+func (r *Registry) Providers() []PaymentProvider {
+	names := make([]string, 0, len(r.providers))
+	for name := range r.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	providers := make([]PaymentProvider, 0, len(names))
+	for _, name := range names {
+		providers = append(providers, r.providers[name])
+	}
+	return providers
+}
@@ -0,0 +1,115 @@
+package payment_provider
+
+import (
+	"beta-payment-api-client/internal/entity"
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+type stubProvider struct {
+	name string
+}
+
+func (p stubProvider) Name() string { return p.name }
+
+func (p stubProvider) FetchStatus(context.Context, uuid.UUID) (string, *entity.PaymentRecordCheckHTTP, error) {
+	return "", nil, nil
+}
+
+func (p stubProvider) Replay(context.Context, string, string, http.Header, []byte) (*entity.PaymentRecordCheckHTTP, error) {
+	return nil, nil
+}
+
+func (p stubProvider) Health(context.Context) error { return nil }
+
+func newTestRegistry(t *testing.T) *Registry {
+	t.Helper()
+	registry := NewRegistry(stubProvider{name: "payment_server"})
+	registry.Register(stubProvider{name: "acme"})
+	registry.Register(stubProvider{name: "globex"})
+	if err := registry.RouteTag("qris", "acme"); err != nil {
+		t.Fatalf("RouteTag: %v", err)
+	}
+	return registry
+}
+
+func TestRegistryResolve(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider string
+		tag      string
+		want     string
+		wantErr  error
+	}{
+		{name: "explicit provider", provider: "globex", want: "globex"},
+		{name: "explicit provider wins over tag route", provider: "globex", tag: "qris", want: "globex"},
+		{name: "explicit default provider", provider: "payment_server", tag: "qris", want: "payment_server"},
+		{name: "tag route", tag: "qris", want: "acme"},
+		{name: "unrouted tag falls back to default", tag: "va", want: "payment_server"},
+		{name: "no provider and no tag", want: "payment_server"},
+		{name: "unknown explicit provider", provider: "initech", tag: "qris", wantErr: ErrUnknownProvider},
+	}
+
+	registry := newTestRegistry(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := registry.Resolve(tt.provider, tt.tag)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Resolve(%q, %q) error = %v, want %v", tt.provider, tt.tag, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Resolve(%q, %q) = %q, want %q", tt.provider, tt.tag, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegistryRouteTagUnknownProvider(t *testing.T) {
+	registry := newTestRegistry(t)
+	if err := registry.RouteTag("va", "initech"); !errors.Is(err, ErrUnknownProvider) {
+		t.Fatalf("RouteTag error = %v, want %v", err, ErrUnknownProvider)
+	}
+	if got, _ := registry.Resolve("", "va"); got != "payment_server" {
+		t.Errorf("rejected route changed resolution: got %q", got)
+	}
+}
+
+func TestRegistryGet(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr error
+	}{
+		{name: "payment_server"},
+		{name: "acme"},
+		{name: "initech", wantErr: ErrUnknownProvider},
+	}
+
+	registry := newTestRegistry(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, err := registry.Get(tt.name)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Get(%q) error = %v, want %v", tt.name, err, tt.wantErr)
+			}
+			if err == nil && provider.Name() != tt.name {
+				t.Errorf("Get(%q) = %q", tt.name, provider.Name())
+			}
+		})
+	}
+}
+
+func TestRegistryProvidersSortedByName(t *testing.T) {
+	var names []string
+	for _, provider := range newTestRegistry(t).Providers() {
+		names = append(names, provider.Name())
+	}
+	want := []string{"acme", "globex", "payment_server"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("Providers() = %v, want %v", names, want)
+	}
+}
@@ -0,0 +1,300 @@
+package payment_server
+
+import (
+	"beta-payment-api-client/internal/valueobject"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Mode autentikasi ke payment server.
+const (
+	AuthNone   = "none"
+	AuthBearer = "bearer" // token statis (PAYMENT_SERVER_API_KEY)
+	AuthOAuth2 = "oauth2" // OAuth2 client credentials, token di-cache
+	AuthMTLS   = "mtls"   // client certificate; tanpa header Authorization
+)
+
+// defaultTokenRefreshBefore token OAuth2 di-refresh selama ini sebelum expired.
+const defaultTokenRefreshBefore = 60 * time.Second
+
+// AuthConfig konfigurasi autentikasi outbound. File TLS (cert/key/CA) berlaku untuk semua mode,
+// jadi mode oauth2 / bearer juga bisa dipakai di atas mTLS.
+type AuthConfig struct {
+	Mode          string               `json:"mode"`
+	Token         string               `json:"token,omitempty"`
+	TokenURL      string               `json:"token_url,omitempty"`
+	ClientID      string               `json:"client_id,omitempty"`
+	ClientSecret  string               `json:"client_secret,omitempty"`
+	Scopes        []string             `json:"scopes,omitempty"`
+	RefreshBefore valueobject.Duration `json:"refresh_before"`
+	CertFile      string               `json:"cert_file,omitempty"`
+	KeyFile       string               `json:"key_file,omitempty"`
+	CAFile        string               `json:"ca_file,omitempty"`
+}
+
+// Authenticator menambahkan kredensial ke request sebelum dikirim.
+type Authenticator interface {
+	Authenticate(req *http.Request) error
+}
+
+// tokenInvalidator authenticator dengan token cache yang bisa dibuang (mis. setelah 401).
+type tokenInvalidator interface {
+	Invalidate()
+}
+
+// Build membuat Authenticator + TLS config (nil = TLS default) sesuai mode.
+// timeout dipakai untuk request ke token endpoint.
+func (ac AuthConfig) Build(timeout time.Duration) (Authenticator, *tls.Config, error) {
+	tlsConfig, err := ac.tlsConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch ac.Mode {
+	case AuthNone:
+		return noAuth{}, tlsConfig, nil
+	case AuthBearer, "":
+		return staticTokenAuth{token: ac.Token}, tlsConfig, nil
+	case AuthMTLS:
+		if tlsConfig == nil || len(tlsConfig.Certificates) == 0 {
+			return nil, nil, errors.New("mtls auth requires cert_file and key_file")
+		}
+		return noAuth{}, tlsConfig, nil
+	case AuthOAuth2:
+		if ac.TokenURL == "" || ac.ClientID == "" || ac.ClientSecret == "" {
+			return nil, nil, errors.New("oauth2 auth requires token_url, client_id and client_secret")
+		}
+		refreshBefore := ac.RefreshBefore.Duration
+		if refreshBefore <= 0 {
+			refreshBefore = defaultTokenRefreshBefore
+		}
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = tlsConfig
+		return &oauth2ClientCredentials{
+			tokenURL:      ac.TokenURL,
+			clientID:      ac.ClientID,
+			clientSecret:  ac.ClientSecret,
+			scopes:        ac.Scopes,
+			refreshBefore: refreshBefore,
+			httpClient:    &http.Client{Timeout: timeout, Transport: transport},
+		}, tlsConfig, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown auth mode %q", ac.Mode)
+	}
+}
+
+func (ac AuthConfig) tlsConfig() (*tls.Config, error) {
+	if ac.CertFile == "" && ac.KeyFile == "" && ac.CAFile == "" {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if ac.CertFile != "" || ac.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(ac.CertFile, ac.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	if ac.CAFile != "" {
+		pem, err := os.ReadFile(ac.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read CA bundle: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA bundle %s", ac.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	return tlsConfig, nil
+}
+
+type noAuth struct{}
+
+func (noAuth) Authenticate(*http.Request) error { return nil }
+
+type staticTokenAuth struct {
+	token string
+}
+
+func (a staticTokenAuth) Authenticate(req *http.Request) error {
+	req.Header.Set("Authorization", "Bearer "+a.token)
+	return nil
+}
+
+// oauth2ClientCredentials mengambil access token lewat grant client_credentials (RFC 6749 §4.4)
+// dan menyimpannya sampai refreshBefore sebelum expired. Hanya satu request token berjalan sekaligus
+// (single flight), dan request token tidak pernah dijalankan sambil memegang mu.
+type oauth2ClientCredentials struct {
+	tokenURL      string
+	clientID      string
+	clientSecret  string
+	scopes        []string
+	refreshBefore time.Duration
+	httpClient    *http.Client
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+	retryAt   time.Time      // refresh background gagal → jangan dicoba lagi sebelum ini selama token lama masih berlaku
+	refresh   *oauth2Refresh // refresh yang sedang berjalan; nil = tidak ada
+}
+
+// oauth2Refresh satu request token yang sedang berjalan; done ditutup setelah token & err terisi.
+type oauth2Refresh struct {
+	done  chan struct{}
+	token string
+	err   error
+}
+
+const (
+	oauth2TokenTimeout = 30 * time.Second // request token kalau httpClient tanpa timeout
+	oauth2RetryBackoff = 5 * time.Second
+)
+
+type oauth2TokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+func (a *oauth2ClientCredentials) Authenticate(req *http.Request) error {
+	token, err := a.Token(req.Context())
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+// Token mengembalikan token dari cache. Token yang masuk jendela refreshBefore tapi masih berlaku
+// langsung dikembalikan sementara refresh berjalan di background; hanya pemanggil tanpa token
+// yang berlaku yang menunggu refresh (bersama-sama, satu request token).
+func (a *oauth2ClientCredentials) Token(ctx context.Context) (string, error) {
+	a.mu.Lock()
+	now := time.Now()
+	if a.token != "" && now.Before(a.expiresAt) {
+		token := a.token
+		if !now.Before(a.expiresAt.Add(-a.refreshBefore)) && !now.Before(a.retryAt) {
+			a.startRefreshLocked()
+		}
+		a.mu.Unlock()
+		return token, nil
+	}
+	refresh := a.startRefreshLocked()
+	a.mu.Unlock()
+
+	select {
+	case <-refresh.done:
+		return refresh.token, refresh.err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// startRefreshLocked memulai request token di background kalau belum ada yang berjalan; a.mu harus dipegang.
+func (a *oauth2ClientCredentials) startRefreshLocked() *oauth2Refresh {
+	if a.refresh != nil {
+		return a.refresh
+	}
+	refresh := &oauth2Refresh{done: make(chan struct{})}
+	a.refresh = refresh
+
+	go func() {
+		timeout := a.httpClient.Timeout
+		if timeout <= 0 {
+			timeout = oauth2TokenTimeout
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		requestedAt := time.Now()
+		token, expiresIn, err := a.requestToken(ctx)
+
+		a.mu.Lock()
+		if err == nil {
+			a.token = token
+			a.expiresAt = requestedAt.Add(expiresIn)
+			a.retryAt = time.Time{}
+		} else {
+			a.retryAt = time.Now().Add(oauth2RetryBackoff)
+		}
+		a.refresh = nil
+		a.mu.Unlock()
+
+		refresh.token, refresh.err = token, err
+		close(refresh.done)
+	}()
+	return refresh
+}
+
+// Invalidate membuang token cache; dipanggil saat payment server menolak token (401).
+func (a *oauth2ClientCredentials) Invalidate() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.token = ""
+	a.retryAt = time.Time{}
+}
+
+func (a *oauth2ClientCredentials) requestToken(ctx context.Context) (string, time.Duration, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(a.scopes) > 0 {
+		form.Set("scope", strings.Join(a.scopes, " "))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", 0, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(a.clientID), url.QueryEscape(a.clientSecret))
+
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return "", 0, fmt.Errorf("oauth2 token request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", 0, fmt.Errorf("oauth2 token request: %w", err)
+	}
+	// Body token endpoint tidak pernah di-log: isinya bisa berupa token
+	if resp.StatusCode != http.StatusOK {
+		if err := ClassifyResponse(resp); err != nil {
+			return "", 0, fmt.Errorf("oauth2 token request: %w", err)
+		}
+		return "", 0, fmt.Errorf("oauth2 token request: unexpected status code %d", resp.StatusCode)
+	}
+
+	var result oauth2TokenResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return "", 0, fmt.Errorf("oauth2 token request: %w", MalformedResponse(resp.StatusCode, err))
+	}
+	if result.AccessToken == "" {
+		return "", 0, fmt.Errorf("oauth2 token request: %w", MalformedResponse(resp.StatusCode, errors.New("missing access_token")))
+	}
+	if result.TokenType != "" && !strings.EqualFold(result.TokenType, "bearer") {
+		return "", 0, fmt.Errorf("oauth2 token request: unsupported token type %q", result.TokenType)
+	}
+
+	expiresIn := time.Duration(result.ExpiresIn) * time.Second
+	if expiresIn <= 0 {
+		// Tanpa expires_in: cache sebentar saja supaya tetap di-refresh berkala
+		expiresIn = a.refreshBefore + 5*time.Minute
+	}
+	return result.AccessToken, expiresIn, nil
+}
@@ -0,0 +1,128 @@
+package payment_server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAuthConfigBuild(t *testing.T) {
+	tests := []struct {
+		name       string
+		cfg        AuthConfig
+		wantHeader string
+		wantErr    bool
+	}{
+		{name: "none", cfg: AuthConfig{Mode: AuthNone}},
+		{name: "bearer", cfg: AuthConfig{Mode: AuthBearer, Token: "secret"}, wantHeader: "Bearer secret"},
+		{name: "empty mode defaults to bearer", cfg: AuthConfig{Token: "secret"}, wantHeader: "Bearer secret"},
+		{name: "mtls without certificate", cfg: AuthConfig{Mode: AuthMTLS}, wantErr: true},
+		{name: "oauth2 without client secret", cfg: AuthConfig{Mode: AuthOAuth2, TokenURL: "http://token", ClientID: "id"}, wantErr: true},
+		{name: "missing certificate file", cfg: AuthConfig{Mode: AuthBearer, CertFile: "/nonexistent/cert.pem", KeyFile: "/nonexistent/key.pem"}, wantErr: true},
+		{name: "unknown mode", cfg: AuthConfig{Mode: "basic"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auth, _, err := tt.cfg.Build(time.Second)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Build() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			req := httptest.NewRequest(http.MethodGet, "http://payment-server/api", nil)
+			if err := auth.Authenticate(req); err != nil {
+				t.Fatalf("Authenticate: %v", err)
+			}
+			if got := req.Header.Get("Authorization"); got != tt.wantHeader {
+				t.Errorf("Authorization = %q, want %q", got, tt.wantHeader)
+			}
+		})
+	}
+}
+
+// newTokenServer token endpoint uji yang menerbitkan token bernomor urut.
+func newTokenServer(t *testing.T, expiresIn int) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var issued atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if id, secret, ok := r.BasicAuth(); !ok || id != "client" || secret != "secret" ||
+			r.PostFormValue("grant_type") != "client_credentials" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		time.Sleep(10 * time.Millisecond) // beri waktu pemanggil lain ikut menunggu
+		n := issued.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"access_token":"token-%d","token_type":"Bearer","expires_in":%d}`, n, expiresIn)
+	}))
+	t.Cleanup(server.Close)
+	return server, &issued
+}
+
+func newTestOAuth2(t *testing.T, tokenURL, secret string) *oauth2ClientCredentials {
+	t.Helper()
+	auth, _, err := AuthConfig{Mode: AuthOAuth2, TokenURL: tokenURL, ClientID: "client", ClientSecret: secret}.Build(time.Second)
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	return auth.(*oauth2ClientCredentials)
+}
+
+func TestOAuth2TokenSingleFlight(t *testing.T) {
+	server, issued := newTokenServer(t, 3600)
+	auth := newTestOAuth2(t, server.URL, "secret")
+
+	var wg sync.WaitGroup
+	tokens := make([]string, 10)
+	errs := make([]error, len(tokens))
+	for i := range tokens {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			tokens[i], errs[i] = auth.Token(context.Background())
+		}(i)
+	}
+	wg.Wait()
+
+	for i := range tokens {
+		if errs[i] != nil || tokens[i] != "token-1" {
+			t.Errorf("caller %d got (%q, %v), want token-1", i, tokens[i], errs[i])
+		}
+	}
+	if got := issued.Load(); got != 1 {
+		t.Errorf("token endpoint called %d times, want 1", got)
+	}
+
+	// Token cache dipakai sampai dibuang (mis. setelah 401)
+	if token, _ := auth.Token(context.Background()); token != "token-1" {
+		t.Errorf("cached token = %q, want token-1", token)
+	}
+	auth.Invalidate()
+	if token, _ := auth.Token(context.Background()); token != "token-2" {
+		t.Errorf("token after Invalidate = %q, want token-2", token)
+	}
+}
+
+func TestOAuth2TokenErrors(t *testing.T) {
+	server, _ := newTokenServer(t, 3600)
+	auth := newTestOAuth2(t, server.URL, "wrong")
+
+	if _, err := auth.Token(context.Background()); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("Token() error = %v, want %v", err, ErrUnauthorized)
+	}
+	req := httptest.NewRequest(http.MethodGet, "http://payment-server/api", nil)
+	if err := auth.Authenticate(req); err == nil {
+		t.Error("Authenticate() error = nil, want rejected credentials")
+	}
+	if got := req.Header.Get("Authorization"); got != "" {
+		t.Errorf("Authorization = %q, want empty", got)
+	}
+}
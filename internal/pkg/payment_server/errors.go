@@ -0,0 +1,104 @@
+package payment_server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Jenis error dari payment server. Pakai errors.Is untuk mengecek jenisnya;
+// detail (status code, Retry-After) ada di *ResponseError.
+var (
+	ErrNotFound          = errors.New("payment not found on payment server")
+	ErrUnauthorized      = errors.New("payment server rejected credentials")
+	ErrForbidden         = errors.New("payment server denied access to payment")
+	ErrThrottled         = errors.New("payment server is throttling requests")
+	ErrServerError       = errors.New("payment server error")
+	ErrUnexpectedStatus  = errors.New("unexpected payment server status code")
+	ErrMalformedResponse = errors.New("malformed payment server response")
+)
+
+type ResponseError struct {
+	Kind       error
+	StatusCode int
+	RetryAfter time.Duration // dari header Retry-After (429 / 503); 0 jika tidak ada
+	Err        error         // penyebab tambahan, mis. error decode JSON
+}
+
+func (e *ResponseError) Error() string {
+	msg := fmt.Sprintf("%v (status %d)", e.Kind, e.StatusCode)
+	if e.RetryAfter > 0 {
+		msg += fmt.Sprintf(", retry after %s", e.RetryAfter)
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *ResponseError) Unwrap() []error {
+	if e.Err != nil {
+		return []error{e.Kind, e.Err}
+	}
+	return []error{e.Kind}
+}
+
+// ClassifyResponse mengubah status code response menjadi error bertipe; nil untuk 2xx.
+func ClassifyResponse(resp *http.Response) error {
+	code := resp.StatusCode
+	switch {
+	case code >= 200 && code < 300:
+		return nil
+	case code == http.StatusNotFound:
+		return &ResponseError{Kind: ErrNotFound, StatusCode: code}
+	case code == http.StatusUnauthorized:
+		return &ResponseError{Kind: ErrUnauthorized, StatusCode: code}
+	case code == http.StatusForbidden:
+		// Kredensial diterima tapi akses ke payment ini ditolak (mis. merchant / scope lain):
+		// masalah per payment, bukan alasan mem-pause seluruh provider
+		return &ResponseError{Kind: ErrForbidden, StatusCode: code}
+	case code == http.StatusTooManyRequests || code == http.StatusServiceUnavailable:
+		return &ResponseError{
+			Kind:       ErrThrottled,
+			StatusCode: code,
+			RetryAfter: ParseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+		}
+	case code >= 500:
+		return &ResponseError{Kind: ErrServerError, StatusCode: code}
+	default:
+		return &ResponseError{Kind: ErrUnexpectedStatus, StatusCode: code}
+	}
+}
+
+// MalformedResponse membungkus error decode body dari response 2xx.
+func MalformedResponse(statusCode int, err error) error {
+	return &ResponseError{Kind: ErrMalformedResponse, StatusCode: statusCode, Err: err}
+}
+
+// RetryAfter mengambil Retry-After dari err; 0 jika tidak ada.
+func RetryAfter(err error) time.Duration {
+	var respErr *ResponseError
+	if errors.As(err, &respErr) {
+		return respErr.RetryAfter
+	}
+	return 0
+}
+
+// ParseRetryAfter membaca header Retry-After dalam detik ("120") atau HTTP-date.
+func ParseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return 0
+}
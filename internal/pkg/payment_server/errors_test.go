@@ -0,0 +1,97 @@
+package payment_server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestClassifyResponse(t *testing.T) {
+	tests := []struct {
+		name           string
+		code           int
+		retryAfter     string
+		wantKind       error
+		wantRetryAfter time.Duration
+	}{
+		{name: "200 ok", code: http.StatusOK},
+		{name: "204 no content", code: http.StatusNoContent},
+		{name: "404 not found", code: http.StatusNotFound, wantKind: ErrNotFound},
+		{name: "401 unauthorized", code: http.StatusUnauthorized, wantKind: ErrUnauthorized},
+		{name: "403 forbidden is not a credential error", code: http.StatusForbidden, wantKind: ErrForbidden},
+		{name: "429 with retry-after", code: http.StatusTooManyRequests, retryAfter: "30", wantKind: ErrThrottled, wantRetryAfter: 30 * time.Second},
+		{name: "429 without retry-after", code: http.StatusTooManyRequests, wantKind: ErrThrottled},
+		{name: "503 with retry-after", code: http.StatusServiceUnavailable, retryAfter: "5", wantKind: ErrThrottled, wantRetryAfter: 5 * time.Second},
+		{name: "500 server error", code: http.StatusInternalServerError, wantKind: ErrServerError},
+		{name: "502 server error", code: http.StatusBadGateway, wantKind: ErrServerError},
+		{name: "400 unexpected", code: http.StatusBadRequest, wantKind: ErrUnexpectedStatus},
+		{name: "302 unexpected", code: http.StatusFound, wantKind: ErrUnexpectedStatus},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: tt.code, Header: http.Header{}}
+			if tt.retryAfter != "" {
+				resp.Header.Set("Retry-After", tt.retryAfter)
+			}
+
+			err := ClassifyResponse(resp)
+			if tt.wantKind == nil {
+				if err != nil {
+					t.Fatalf("ClassifyResponse() = %v, want nil", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantKind) {
+				t.Fatalf("ClassifyResponse() = %v, want %v", err, tt.wantKind)
+			}
+			var respErr *ResponseError
+			if !errors.As(err, &respErr) || respErr.StatusCode != tt.code {
+				t.Errorf("ClassifyResponse() = %#v, want *ResponseError with status %d", err, tt.code)
+			}
+			if got := RetryAfter(err); got != tt.wantRetryAfter {
+				t.Errorf("RetryAfter() = %s, want %s", got, tt.wantRetryAfter)
+			}
+		})
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		value string
+		want  time.Duration
+	}{
+		{name: "empty", value: "", want: 0},
+		{name: "seconds", value: "120", want: 120 * time.Second},
+		{name: "zero seconds", value: "0", want: 0},
+		{name: "negative seconds", value: "-5", want: 0},
+		{name: "http date in the future", value: now.Add(90 * time.Second).Format(http.TimeFormat), want: 90 * time.Second},
+		{name: "http date in the past", value: now.Add(-time.Minute).Format(http.TimeFormat), want: 0},
+		{name: "garbage", value: "soon", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseRetryAfter(tt.value, now); got != tt.want {
+				t.Errorf("ParseRetryAfter(%q) = %s, want %s", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMalformedResponse(t *testing.T) {
+	cause := errors.New("unexpected end of JSON input")
+	err := fmt.Errorf("fetch: %w", MalformedResponse(http.StatusOK, cause))
+
+	if !errors.Is(err, ErrMalformedResponse) {
+		t.Errorf("error = %v, want %v", err, ErrMalformedResponse)
+	}
+	if !errors.Is(err, cause) {
+		t.Errorf("error = %v, want cause %v", err, cause)
+	}
+}
@@ -2,41 +2,383 @@ package payment_server
 
 import (
 	"beta-payment-api-client/config"
+	"beta-payment-api-client/internal/dto"
+	"beta-payment-api-client/internal/entity"
+	"beta-payment-api-client/internal/pkg/circuitbreaker"
+	"beta-payment-api-client/internal/pkg/ratelimit"
+	"beta-payment-api-client/internal/pkg/redact"
+	"beta-payment-api-client/internal/valueobject"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"github.com/google/uuid"
 	"github.com/rs/zerolog"
+	"io"
+	"net"
 	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
 )
 
+// DefaultProviderName nama adapter untuk payment server bawaan.
+const DefaultProviderName = "payment_server"
+
+var ErrForeignReplayURL = errors.New("replay url does not belong to payment provider")
+
+// ClientConfig konfigurasi satu adapter HTTP payment server / PSP.
+type ClientConfig struct {
+	Name        string
+	BaseURL     string
+	Auth        AuthConfig
+	PaymentPath string // path GET payment, "{id}" diganti dengan UUID payment
+	HealthPath  string
+	// StatusField path JSON (dipisah titik, mis. "data.attributes.state") ke status payment.
+	// Kosong = format payment server bawaan (dto.GetPaymentByIDResponse).
+	StatusField         string
+	Timeout             time.Duration // total per request (termasuk antre rate limit)
+	ConnTimeout         time.Duration
+	ReadTimeout         time.Duration // menunggu header response
+	MaxBody             int64
+	MaxIdleConnsPerHost int
+	Breaker             circuitbreaker.Config
+}
+
 type PaymentServerClient struct {
-	baseURL string
-	apiKey  string
-	logger  zerolog.Logger
+	name         string
+	baseURL      string
+	auth         Authenticator
+	paymentPath  string
+	healthPath   string
+	statusField  []string
+	maxBodyBytes int64
+	httpClient   *http.Client
+	directClient *http.Client // tanpa breaker & limiter: replay manual & probe health tidak boleh mengganggu polling
+	breaker      *circuitbreaker.Breaker
+	logger       zerolog.Logger
+}
+
+// DefaultClientConfig konfigurasi payment server bawaan dari env.
+func DefaultClientConfig(cfg *config.AppConfig) ClientConfig {
+	return ClientConfig{
+		Name:    DefaultProviderName,
+		BaseURL: cfg.PaymentServerBaseURL,
+		Auth: AuthConfig{
+			Mode:          cfg.PaymentServerAuth,
+			Token:         cfg.PaymentServerAPIKey,
+			TokenURL:      cfg.OAuth2TokenURL,
+			ClientID:      cfg.OAuth2ClientID,
+			ClientSecret:  cfg.OAuth2ClientSecret,
+			Scopes:        strings.Fields(cfg.OAuth2Scopes),
+			RefreshBefore: valueobject.Duration{Duration: cfg.OAuth2RefreshBefore},
+			CertFile:      cfg.PaymentServerTLSCert,
+			KeyFile:       cfg.PaymentServerTLSKey,
+			CAFile:        cfg.PaymentServerTLSCA,
+		},
+		PaymentPath:         "/api/v1/payments/{id}",
+		HealthPath:          "/healthz",
+		Timeout:             cfg.PaymentServerTimeout,
+		ConnTimeout:         cfg.PaymentServerConnTimeout,
+		ReadTimeout:         cfg.PaymentServerReadTimeout,
+		MaxBody:             cfg.PaymentServerMaxBody,
+		MaxIdleConnsPerHost: cfg.PollingWorkers, // satu koneksi per worker polling
+		Breaker: circuitbreaker.Config{
+			FailureThreshold: cfg.BreakerFailureThreshold,
+			OpenTimeout:      cfg.BreakerOpenTimeout,
+			HalfOpenRequests: cfg.BreakerHalfOpenRequests,
+		},
+	}
+}
+
+// NewPaymentServerClient membuat client untuk payment server bawaan.
+func NewPaymentServerClient(cfg *config.AppConfig, limiter ratelimit.Limiter, logger zerolog.Logger) (*PaymentServerClient, error) {
+	return NewClient(DefaultClientConfig(cfg), limiter, logger)
 }
 
-func NewPaymentServerClient(cfg *config.AppConfig, logger zerolog.Logger) *PaymentServerClient {
+// NewClient membuat client payment server. Semua request polling lewat httpClient
+// melewati circuit breaker dulu (ditolak langsung saat open), lalu dibatasi limiter
+// (global, bisa dibagi antar replica lewat Redis). Replay dan probe health memakai client terpisah
+// supaya tidak memakan token rate limit polling maupun ikut membuka / menutup breaker-nya.
+// Error jika konfigurasi auth / TLS invalid.
+func NewClient(cc ClientConfig, limiter ratelimit.Limiter, logger zerolog.Logger) (*PaymentServerClient, error) {
+	auth, tlsConfig, err := cc.Auth.Build(cc.Timeout)
+	if err != nil {
+		return nil, fmt.Errorf("payment provider %s: %w", cc.Name, err)
+	}
+
+	logger = logger.With().Str("provider", cc.Name).Logger()
+	breaker := circuitbreaker.NewBreaker(cc.Name, cc.Breaker, logger)
+
+	var statusField []string
+	if cc.StatusField != "" {
+		statusField = strings.Split(cc.StatusField, ".")
+	}
+
 	return &PaymentServerClient{
-		baseURL: cfg.PaymentServerBaseURL,
-		apiKey:  cfg.PaymentServerAPIKey,
+		name:         cc.Name,
+		baseURL:      strings.TrimRight(cc.BaseURL, "/"),
+		auth:         auth,
+		paymentPath:  cc.PaymentPath,
+		healthPath:   cc.HealthPath,
+		statusField:  statusField,
+		maxBodyBytes: cc.MaxBody,
+		httpClient: &http.Client{
+			Timeout: cc.Timeout, // total: connect + rate limit wait + baca body
+			Transport: &circuitbreaker.Transport{
+				Base:    &ratelimit.Transport{Base: newTransport(cc, tlsConfig), Limiter: limiter},
+				Breaker: breaker,
+			},
+		},
+		directClient: &http.Client{
+			Timeout:   cc.Timeout,
+			Transport: newTransport(cc, tlsConfig),
+		},
+		breaker: breaker,
 		logger:  logger,
+	}, nil
+}
+
+// newTransport transport khusus payment server; http.DefaultTransport tidak punya
+// batas waktu menunggu header response.
+func newTransport(cc ClientConfig, tlsConfig *tls.Config) *http.Transport {
+	dialer := &net.Dialer{
+		Timeout:   cc.ConnTimeout,
+		KeepAlive: 30 * time.Second,
+	}
+	return &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     true,
+		TLSClientConfig:       tlsConfig, // client certificate (mTLS) + CA bundle
+		TLSHandshakeTimeout:   cc.ConnTimeout,
+		ResponseHeaderTimeout: cc.ReadTimeout,
+		ExpectContinueTimeout: 1 * time.Second,
+		MaxIdleConns:          cc.MaxIdleConnsPerHost * 2,
+		MaxIdleConnsPerHost:   cc.MaxIdleConnsPerHost,
+		IdleConnTimeout:       90 * time.Second,
 	}
 }
 
-func (p *PaymentServerClient) InitPaymentServer() error {
-	url := fmt.Sprintf("%s/healthz", p.baseURL)
+func (p *PaymentServerClient) Name() string {
+	return p.name
+}
+
+// Breaker circuit breaker payment server (untuk health endpoint).
+func (p *PaymentServerClient) Breaker() *circuitbreaker.Breaker {
+	return p.breaker
+}
+
+// Health memanggil endpoint health payment server; nil jika 200. Probe tidak lewat breaker
+// supaya readiness melaporkan kondisi server sebenarnya, bukan ErrOpen.
+func (p *PaymentServerClient) Health(ctx context.Context) error {
+	if p.healthPath == "" {
+		return nil
+	}
+	url := p.baseURL + p.healthPath
 
-	resp, err := http.Get(url)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := p.directClient.Do(req)
 	if err != nil {
-		p.logger.Error().Err(err).Msgf("❌ Error connecting to Payment Server: %v", err)
 		return err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		err := fmt.Errorf("unexpected status code: %d", resp.StatusCode)
-		p.logger.Error().Err(err).Msg("❌ Failed to connect to Payment Server")
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (p *PaymentServerClient) InitPaymentServer() error {
+	if err := p.Health(context.Background()); err != nil {
+		p.logger.Error().Err(err).Msgf("❌ Failed to connect to Payment Server %s: %v", p.name, err)
 		return err
 	}
 
-	p.logger.Info().Msgf("✅ Payment Server is healthy at %s", url)
+	p.logger.Info().Msgf("✅ Payment Server %s is healthy at %s", p.name, p.baseURL)
 	return nil
 }
+
+// FetchStatus mengambil status mentah payment dari provider (belum dipetakan ke status kanonik).
+func (p *PaymentServerClient) FetchStatus(ctx context.Context, id uuid.UUID) (string, *entity.PaymentRecordCheckHTTP, error) {
+	if len(p.statusField) == 0 {
+		payment, checkHTTP, err := p.GetPayment(ctx, id)
+		return payment.Status, checkHTTP, err
+	}
+
+	body, checkHTTP, err := p.fetch(ctx, id)
+	if err != nil {
+		return "", checkHTTP, err
+	}
+
+	var document any
+	if err := json.Unmarshal(body, &document); err != nil {
+		return "", checkHTTP, MalformedResponse(checkHTTP.StatusCode, err)
+	}
+	for _, field := range p.statusField {
+		object, ok := document.(map[string]any)
+		if !ok {
+			document = nil
+			break
+		}
+		document = object[field]
+	}
+	status, ok := document.(string)
+	if !ok || status == "" {
+		return "", checkHTTP, MalformedResponse(checkHTTP.StatusCode, fmt.Errorf("missing payment status at %q", strings.Join(p.statusField, ".")))
+	}
+	return status, checkHTTP, nil
+}
+
+// GetPayment mengambil payment dari payment server (format bawaan dto.GetPaymentByIDResponse).
+// Selain data, selalu kembalikan request/response mentah (jika ada) untuk check log;
+// error-nya bertipe (lihat errors.go) kecuali error jaringan.
+func (p *PaymentServerClient) GetPayment(ctx context.Context, id uuid.UUID) (dto.PaymentData, *entity.PaymentRecordCheckHTTP, error) {
+	body, checkHTTP, err := p.fetch(ctx, id)
+	if err != nil {
+		return dto.PaymentData{}, checkHTTP, err
+	}
+
+	var result dto.GetPaymentByIDResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return dto.PaymentData{}, checkHTTP, MalformedResponse(checkHTTP.StatusCode, err)
+	}
+	if result.Data.Status == "" {
+		return dto.PaymentData{}, checkHTTP, MalformedResponse(checkHTTP.StatusCode, errors.New("missing payment status"))
+	}
+	return result.Data, checkHTTP, nil
+}
+
+// fetch menjalankan GET payment dan mengembalikan body response 2xx.
+// Jika token ditolak (mis. dicabut sebelum expired) dan authenticator bisa mengambil token baru,
+// request diulang sekali dengan token baru; 401 kedua baru dianggap kredensial benar-benar salah.
+func (p *PaymentServerClient) fetch(ctx context.Context, id uuid.UUID) ([]byte, *entity.PaymentRecordCheckHTTP, error) {
+	body, checkHTTP, err := p.fetchOnce(ctx, id)
+	invalidator, ok := p.auth.(tokenInvalidator)
+	if !ok || !errors.Is(err, ErrUnauthorized) {
+		return body, checkHTTP, err
+	}
+	invalidator.Invalidate()
+	p.logger.Warn().Str("payment_id", id.String()).Msg("‼️ Payment server rejected token, retrying with a fresh token")
+	return p.fetchOnce(ctx, id)
+}
+
+func (p *PaymentServerClient) fetchOnce(ctx context.Context, id uuid.UUID) ([]byte, *entity.PaymentRecordCheckHTTP, error) {
+	url := p.baseURL + strings.ReplaceAll(p.paymentPath, "{id}", id.String())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	checkHTTP := &entity.PaymentRecordCheckHTTP{Context: ctx, ID: id, Request: req}
+	if err := p.auth.Authenticate(req); err != nil {
+		return nil, checkHTTP, err
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, checkHTTP, err
+	}
+	defer resp.Body.Close()
+	checkHTTP.Response = resp
+	checkHTTP.StatusCode = resp.StatusCode
+
+	// Baca maksimal maxBodyBytes+1 untuk mendeteksi body yang kebesaran
+	body, err := io.ReadAll(io.LimitReader(resp.Body, p.maxBodyBytes+1))
+	if err != nil {
+		return nil, checkHTTP, err
+	}
+	if int64(len(body)) > p.maxBodyBytes {
+		checkHTTP.ResponseBody = body[:p.maxBodyBytes]
+		return nil, checkHTTP, MalformedResponse(resp.StatusCode, fmt.Errorf("response body exceeds %d bytes", p.maxBodyBytes))
+	}
+	checkHTTP.ResponseBody = body
+
+	// Body sebagai field (bukan bagian pesan) supaya redaksi JSON path di writer log ikut berlaku
+	p.logger.Debug().Str("payment_id", id.String()).Int("status_code", resp.StatusCode).Bytes("body", body).Msg("📦 Payment API response")
+
+	// Status code dicek dulu: body error (404, 401, 5xx, ...) tidak boleh dibaca sebagai status payment
+	if err := ClassifyResponse(resp); err != nil {
+		return nil, checkHTTP, err
+	}
+	return body, checkHTTP, nil
+}
+
+// Replay mengirim ulang request dari check log. Header kredensial (dan header lain yang tersimpan teredaksi)
+// dibuang lalu diisi ulang oleh authenticator provider; URL harus berada di bawah base URL provider.
+// Response non-2xx bukan error: pemanggil membandingkan sendiri status & body-nya.
+func (p *PaymentServerClient) Replay(ctx context.Context, method, rawURL string, header http.Header, body []byte) (*entity.PaymentRecordCheckHTTP, error) {
+	target, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	base, err := url.Parse(p.baseURL)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(target.Scheme, base.Scheme) || !strings.EqualFold(target.Host, base.Host) || !underBasePath(base.Path, target.Path) {
+		return nil, fmt.Errorf("%w: %s", ErrForeignReplayURL, p.name)
+	}
+
+	var reqBody io.Reader
+	if len(body) > 0 {
+		reqBody = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target.String(), reqBody)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range header {
+		switch http.CanonicalHeaderKey(key) {
+		case "Authorization", "Proxy-Authorization", "Content-Length":
+			continue
+		}
+		for _, value := range values {
+			if !strings.Contains(value, redact.Mask) {
+				req.Header.Add(key, value)
+			}
+		}
+	}
+
+	checkHTTP := &entity.PaymentRecordCheckHTTP{Context: ctx, Request: req}
+	if err := p.auth.Authenticate(req); err != nil {
+		return checkHTTP, err
+	}
+
+	resp, err := p.directClient.Do(req)
+	if err != nil {
+		return checkHTTP, err
+	}
+	defer resp.Body.Close()
+	checkHTTP.Response = resp
+	checkHTTP.StatusCode = resp.StatusCode
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, p.maxBodyBytes))
+	if err != nil {
+		return checkHTTP, err
+	}
+	checkHTTP.ResponseBody = respBody
+
+	p.logger.Info().Str("url", target.String()).Int("status_code", resp.StatusCode).Msg("🔁 Replayed payment server request")
+	return checkHTTP, nil
+}
+
+// underBasePath membandingkan path per segmen: base "/api" mencakup "/api" dan "/api/v1/..." tapi tidak "/apix";
+// dot segment ("/api/../admin") dibersihkan dulu. Base path kosong / "/" mencakup semua path.
+func underBasePath(basePath, targetPath string) bool {
+	base := path.Clean("/" + basePath)
+	if base == "/" {
+		return true
+	}
+	target := path.Clean("/" + targetPath)
+	return target == base || strings.HasPrefix(target, base+"/")
+}
@@ -0,0 +1,132 @@
+package payment_server
+
+import (
+	"beta-payment-api-client/internal/pkg/circuitbreaker"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+// countingLimiter mencatat berapa token rate limit yang dipakai.
+type countingLimiter struct {
+	waits atomic.Int32
+}
+
+func (l *countingLimiter) Wait(context.Context) error {
+	l.waits.Add(1)
+	return nil
+}
+
+// newTestClient client ke server uji dengan breaker yang langsung open setelah satu kegagalan.
+func newTestClient(t *testing.T, handler http.HandlerFunc) (*PaymentServerClient, *countingLimiter) {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	limiter := &countingLimiter{}
+	client, err := NewClient(ClientConfig{
+		Name:                "test",
+		BaseURL:             server.URL,
+		Auth:                AuthConfig{Mode: AuthNone},
+		PaymentPath:         "/api/v1/payments/{id}",
+		HealthPath:          "/healthz",
+		Timeout:             time.Second,
+		ConnTimeout:         time.Second,
+		ReadTimeout:         time.Second,
+		MaxBody:             1 << 10,
+		MaxIdleConnsPerHost: 1,
+		Breaker: circuitbreaker.Config{
+			FailureThreshold: 1,
+			OpenTimeout:      time.Hour,
+			HalfOpenRequests: 1,
+		},
+	}, limiter, zerolog.Nop())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	return client, limiter
+}
+
+// openBreaker membuat breaker client open lewat satu cek payment yang dijawab 5xx.
+func openBreaker(t *testing.T, client *PaymentServerClient) {
+	t.Helper()
+	if _, _, err := client.FetchStatus(context.Background(), uuid.New()); !errors.Is(err, ErrServerError) {
+		t.Fatalf("FetchStatus error = %v, want %v", err, ErrServerError)
+	}
+	if _, _, err := client.FetchStatus(context.Background(), uuid.New()); !errors.Is(err, circuitbreaker.ErrOpen) {
+		t.Fatalf("FetchStatus error = %v, want %v", err, circuitbreaker.ErrOpen)
+	}
+}
+
+func TestReplayBypassesPollingBreakerAndLimiter(t *testing.T) {
+	client, limiter := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("replay") == "" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"data":{"status":"PAID"}}`))
+	})
+	openBreaker(t, client)
+	waits := limiter.waits.Load()
+
+	checkHTTP, err := client.Replay(context.Background(), http.MethodGet, client.baseURL+"/api/v1/payments/x?replay=1", nil, nil)
+	if err != nil {
+		t.Fatalf("Replay: %v", err)
+	}
+	if checkHTTP.StatusCode != http.StatusOK {
+		t.Errorf("status code = %d, want %d", checkHTTP.StatusCode, http.StatusOK)
+	}
+	if got := limiter.waits.Load(); got != waits {
+		t.Errorf("replay used %d rate limit tokens, want 0", got-waits)
+	}
+	if state := client.Breaker().State(); state != circuitbreaker.StateOpen {
+		t.Errorf("breaker state = %s, want %s", state, circuitbreaker.StateOpen)
+	}
+}
+
+func TestHealthBypassesPollingBreakerAndLimiter(t *testing.T) {
+	tests := []struct {
+		name       string
+		healthCode int
+		wantErr    bool
+	}{
+		{name: "healthy server while breaker open", healthCode: http.StatusOK},
+		{name: "unhealthy server reports its own status", healthCode: http.StatusBadGateway, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, limiter := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/healthz" {
+					w.WriteHeader(tt.healthCode)
+					return
+				}
+				w.WriteHeader(http.StatusInternalServerError)
+			})
+			openBreaker(t, client)
+			waits := limiter.waits.Load()
+
+			err := client.Health(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Health error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if errors.Is(err, circuitbreaker.ErrOpen) {
+				t.Errorf("Health reported breaker state instead of server health: %v", err)
+			}
+			if got := limiter.waits.Load(); got != waits {
+				t.Errorf("health probe used %d rate limit tokens, want 0", got-waits)
+			}
+			if state := client.Breaker().State(); state != circuitbreaker.StateOpen {
+				t.Errorf("breaker state = %s, want %s", state, circuitbreaker.StateOpen)
+			}
+		})
+	}
+}
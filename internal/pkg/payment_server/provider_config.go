@@ -0,0 +1,66 @@
+package payment_server
+
+import (
+	"beta-payment-api-client/internal/valueobject"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ProviderConfig konfigurasi PSP tambahan dari env PAYMENT_PROVIDERS (JSON), mis.
+// {"acme":{"base_url":"https://api.acme.test","payment_path":"/v2/charges/{id}","status_field":"data.state"}}
+// Field yang kosong mengikuti konfigurasi payment server bawaan, kecuali auth:
+// kredensial payment server bawaan tidak pernah dikirim ke PSP lain.
+type ProviderConfig struct {
+	BaseURL     string                `json:"base_url"`
+	APIKey      string                `json:"api_key,omitempty"` // shortcut untuk auth bearer
+	Auth        *AuthConfig           `json:"auth,omitempty"`
+	PaymentPath string                `json:"payment_path"`
+	HealthPath  string                `json:"health_path,omitempty"`
+	StatusField string                `json:"status_field,omitempty"`
+	Timeout     *valueobject.Duration `json:"timeout,omitempty"`
+	RateLimit   *float64              `json:"rate_limit,omitempty"`
+	RateBurst   *int                  `json:"rate_burst,omitempty"`
+}
+
+// ParseProviderConfigs membaca konfigurasi PSP tambahan; nama provider adalah key map.
+func ParseProviderConfigs(raw string) (map[string]ProviderConfig, error) {
+	providers := map[string]ProviderConfig{}
+	if raw == "" {
+		return providers, nil
+	}
+	if err := json.Unmarshal([]byte(raw), &providers); err != nil {
+		return nil, fmt.Errorf("invalid payment providers: %w", err)
+	}
+	for name, provider := range providers {
+		if name == DefaultProviderName {
+			return nil, fmt.Errorf("invalid payment providers: %q is reserved for the default payment server", name)
+		}
+		if provider.BaseURL == "" || provider.PaymentPath == "" {
+			return nil, fmt.Errorf("invalid payment providers: %s: %w", name, errors.New("base_url and payment_path are required"))
+		}
+	}
+	return providers, nil
+}
+
+// ClientConfig menurunkan ClientConfig dari konfigurasi default + field yang di-set.
+func (pc ProviderConfig) ClientConfig(name string, defaults ClientConfig) ClientConfig {
+	cc := defaults
+	cc.Name = name
+	cc.BaseURL = pc.BaseURL
+	switch {
+	case pc.Auth != nil:
+		cc.Auth = *pc.Auth
+	case pc.APIKey != "":
+		cc.Auth = AuthConfig{Mode: AuthBearer, Token: pc.APIKey}
+	default:
+		cc.Auth = AuthConfig{Mode: AuthNone}
+	}
+	cc.PaymentPath = pc.PaymentPath
+	cc.HealthPath = pc.HealthPath
+	cc.StatusField = pc.StatusField
+	if pc.Timeout != nil {
+		cc.Timeout = pc.Timeout.Duration
+	}
+	return cc
+}
@@ -0,0 +1,56 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// LocalLimiter token bucket in-memory; hanya berlaku untuk satu instance.
+type LocalLimiter struct {
+	mu     sync.Mutex
+	rate   float64 // token per detik
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func NewLocalLimiter(rate float64, burst int) *LocalLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &LocalLimiter{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// Wait memesan satu token. Jika bucket kosong, token tetap dipesan (saldo boleh negatif)
+// lalu pemanggil menunggu sampai token tsb terisi → request dilayani berurutan (FIFO).
+func (l *LocalLimiter) Wait(ctx context.Context) error {
+	return sleep(ctx, l.reserve())
+}
+
+func (l *LocalLimiter) reserve() time.Duration {
+	if l.rate <= 0 {
+		return 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
@@ -0,0 +1,75 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLocalLimiterReserve(t *testing.T) {
+	tests := []struct {
+		name  string
+		rate  float64
+		burst int
+		want  []time.Duration // jeda tiap reservasi berturut-turut
+	}{
+		{name: "burst served immediately then queued", rate: 10, burst: 2, want: []time.Duration{0, 0, 100 * time.Millisecond, 200 * time.Millisecond}},
+		{name: "burst below one is treated as one", rate: 4, burst: 0, want: []time.Duration{0, 250 * time.Millisecond, 500 * time.Millisecond}},
+		{name: "non-positive rate is unlimited", rate: 0, burst: 1, want: []time.Duration{0, 0, 0}},
+	}
+
+	const tolerance = 20 * time.Millisecond
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := NewLocalLimiter(tt.rate, tt.burst)
+			for i, want := range tt.want {
+				got := limiter.reserve()
+				if got < want-tolerance || got > want+tolerance {
+					t.Errorf("reservation %d waits %s, want about %s", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestLocalLimiterRefills(t *testing.T) {
+	limiter := NewLocalLimiter(100, 1)
+	if wait := limiter.reserve(); wait != 0 {
+		t.Fatalf("first reservation waits %s, want 0", wait)
+	}
+	time.Sleep(20 * time.Millisecond) // > 1 token
+	if wait := limiter.reserve(); wait != 0 {
+		t.Errorf("reservation after refill waits %s, want 0", wait)
+	}
+}
+
+func TestTransportStopsWhenWaitIsCancelled(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+	}))
+	t.Cleanup(server.Close)
+
+	limiter := NewLocalLimiter(0.001, 1) // token berikutnya baru tersedia ~17 menit lagi
+	client := &http.Client{Transport: &Transport{Limiter: limiter}}
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("first request: %v", err)
+	}
+	resp.Body.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	if _, err := client.Do(req); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("queued request error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if got := requests.Load(); got != 1 {
+		t.Errorf("server received %d requests, want 1", got)
+	}
+}
@@ -0,0 +1,52 @@
+package ratelimit
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+const (
+	BackendMemory = "memory"
+	BackendRedis  = "redis"
+)
+
+// Limiter membatasi laju request keluar. Wait memblok sampai giliran request tiba
+// (request di atas limit mengantre, bukan ditolak) atau ctx selesai.
+type Limiter interface {
+	Wait(ctx context.Context) error
+}
+
+// sleep menunggu selama d, atau berhenti lebih awal jika ctx selesai.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Transport adalah http.RoundTripper yang menunggu Limiter sebelum setiap request.
+type Transport struct {
+	Base    http.RoundTripper
+	Limiter Limiter
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.Limiter != nil {
+		if err := t.Limiter.Wait(req.Context()); err != nil {
+			return nil, err
+		}
+	}
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return base.RoundTrip(req)
+}
@@ -0,0 +1,76 @@
+package ratelimit
+
+import (
+	"context"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+	"time"
+)
+
+// Token bucket di Redis: satu bucket dipakai bersama oleh semua replica.
+// Waktu diambil dari Redis (TIME) supaya tidak terpengaruh clock skew antar instance.
+// Return: berapa ms pemanggil harus menunggu untuk token yang sudah dipesan.
+var reserveTokenScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local t = redis.call("TIME")
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+
+local state = redis.call("HMGET", KEYS[1], "tokens", "ts")
+local tokens = tonumber(state[1]) or burst
+local ts = tonumber(state[2]) or now
+if now > ts then
+  tokens = math.min(burst, tokens + (now - ts) * rate / 1000)
+  ts = now
+end
+
+tokens = tokens - 1
+local wait = 0
+if tokens < 0 then
+  wait = math.ceil(-tokens * 1000 / rate)
+end
+
+redis.call("HSET", KEYS[1], "tokens", tostring(tokens), "ts", ts)
+redis.call("PEXPIRE", KEYS[1], wait + math.ceil(burst * 1000 / rate) + 1000)
+return wait`)
+
+// RedisLimiter token bucket bersama lewat Redis. Jika Redis tidak bisa dihubungi,
+// fallback ke LocalLimiter dengan limit yang sama supaya request tetap jalan.
+type RedisLimiter struct {
+	client   *redis.Client
+	key      string
+	rate     float64
+	burst    int
+	fallback *LocalLimiter
+	logger   zerolog.Logger
+}
+
+func NewRedisLimiter(client *redis.Client, key string, rate float64, burst int, logger zerolog.Logger) *RedisLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RedisLimiter{
+		client:   client,
+		key:      key,
+		rate:     rate,
+		burst:    burst,
+		fallback: NewLocalLimiter(rate, burst),
+		logger:   logger,
+	}
+}
+
+func (l *RedisLimiter) Wait(ctx context.Context) error {
+	if l.rate <= 0 {
+		return nil
+	}
+
+	waitMs, err := reserveTokenScript.Run(ctx, l.client, []string{l.key}, l.rate, l.burst).Int64()
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		l.logger.Warn().Err(err).Msg("‼️ Redis rate limiter unavailable, falling back to local limiter")
+		return l.fallback.Wait(ctx)
+	}
+	return sleep(ctx, time.Duration(waitMs)*time.Millisecond)
+}
@@ -0,0 +1,314 @@
+package redact
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const Mask = "***redacted***"
+
+// DefaultHeaders header yang selalu diredaksi. Authorization & Proxy-Authorization tetap menyimpan skemanya
+// ("Bearer ***redacted***") supaya mode auth masih terlihat.
+var DefaultHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-Api-Key"}
+
+// DefaultJSONPaths field PII / kredensial di body JSON dan log, di kedalaman mana pun.
+var DefaultJSONPaths = []string{
+	"**.card_number", "**.cvv", "**.email", "**.phone", "**.phone_number",
+	"**.password", "**.access_token", "**.refresh_token", "**.client_secret",
+}
+
+// DefaultPatterns regex yang diterapkan ke semua nilai string; "card_number" hanya cocok untuk angka yang lolos cek Luhn
+// dan bukan bagian dari token bertanda hubung yang lebih panjang (mis. UUID).
+var DefaultPatterns = map[string]string{
+	"card_number": `\b\d(?:[ -]?\d){12,18}\b`,
+	"email":       `[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`,
+}
+
+var credentialHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+}
+
+// Config aturan redaksi tambahan; selalu digabung dengan default.
+type Config struct {
+	Headers   []string          // nama header, case-insensitive
+	JSONPaths []string          // path bertitik; "*" = satu key / elemen apa pun, "**" = nol atau lebih level
+	Patterns  map[string]string // nama → regex
+}
+
+type pattern struct {
+	name  string
+	re    *regexp.Regexp
+	valid func(s string, start, end int) bool // nil = setiap match diredaksi
+}
+
+// Redactor menerapkan aturan redaksi yang sama ke header, body, teks bebas dan baris log.
+type Redactor struct {
+	headers  map[string]bool
+	paths    [][]string
+	patterns []pattern
+}
+
+// New menggabungkan default dengan cfg; regex invalid → error.
+func New(cfg Config) (*Redactor, error) {
+	r := &Redactor{headers: map[string]bool{}}
+	for _, name := range append(append([]string(nil), DefaultHeaders...), cfg.Headers...) {
+		if name = strings.TrimSpace(name); name != "" {
+			r.headers[http.CanonicalHeaderKey(name)] = true
+		}
+	}
+
+	for _, path := range append(append([]string(nil), DefaultJSONPaths...), cfg.JSONPaths...) {
+		if path = strings.TrimSpace(path); path != "" {
+			r.paths = append(r.paths, strings.Split(path, "."))
+		}
+	}
+
+	patterns := map[string]string{}
+	for name, expr := range DefaultPatterns {
+		patterns[name] = expr
+	}
+	for name, expr := range cfg.Patterns {
+		patterns[name] = expr
+	}
+	names := make([]string, 0, len(patterns))
+	for name := range patterns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		re, err := regexp.Compile(patterns[name])
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern %q: %w", name, err)
+		}
+		p := pattern{name: name, re: re}
+		if name == "card_number" && patterns[name] == DefaultPatterns["card_number"] {
+			p.valid = cardNumber
+		}
+		r.patterns = append(r.patterns, p)
+	}
+	return r, nil
+}
+
+// Default redactor hanya dengan aturan bawaan.
+func Default() *Redactor {
+	r, _ := New(Config{})
+	return r
+}
+
+// Header mengembalikan salinan header dengan nilai sensitif diganti Mask; aman untuk header nil.
+func (r *Redactor) Header(h http.Header) http.Header {
+	cloned := make(http.Header, len(h))
+	for k, v := range h {
+		cp := append([]string(nil), v...)
+		key := http.CanonicalHeaderKey(k)
+		for i := range cp {
+			switch {
+			case credentialHeaders[key]:
+				cp[i] = redactCredential(cp[i])
+			case r.headers[key]:
+				cp[i] = Mask
+			default:
+				cp[i] = r.String(cp[i])
+			}
+		}
+		cloned[k] = cp
+	}
+	return cloned
+}
+
+// Body meredaksi body: JSON → path + pattern per nilai string, selain itu pattern ke seluruh teks.
+// Body yang tidak berubah dikembalikan apa adanya.
+func (r *Redactor) Body(body []byte) []byte {
+	if len(body) == 0 {
+		return body
+	}
+	if redacted, ok := r.json(body); ok {
+		return redacted
+	}
+	text := string(body)
+	if redacted := r.String(text); redacted != text {
+		return []byte(redacted)
+	}
+	return body
+}
+
+// String menerapkan pattern regex ke teks bebas (URL, pesan log, nilai header).
+func (r *Redactor) String(s string) string {
+	for _, p := range r.patterns {
+		if p.valid == nil {
+			s = p.re.ReplaceAllString(s, Mask)
+			continue
+		}
+		s = replaceValid(s, p)
+	}
+	return s
+}
+
+// replaceValid mengganti match yang lolos p.valid; validator melihat konteks di sekitar match.
+func replaceValid(s string, p pattern) string {
+	var b strings.Builder
+	last := 0
+	for _, m := range p.re.FindAllStringIndex(s, -1) {
+		if !p.valid(s, m[0], m[1]) {
+			continue
+		}
+		b.WriteString(s[last:m[0]])
+		b.WriteString(Mask)
+		last = m[1]
+	}
+	if last == 0 {
+		return s
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+// json meredaksi dokumen JSON; ok=false kalau body bukan JSON.
+func (r *Redactor) json(body []byte) ([]byte, bool) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var document any
+	if err := decoder.Decode(&document); err != nil || decoder.More() {
+		return nil, false
+	}
+
+	redacted, changed := r.walk(document, nil)
+	if !changed {
+		return body, true
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(redacted); err != nil {
+		return nil, false
+	}
+	out := buf.Bytes()
+	if !bytes.HasSuffix(body, []byte("\n")) {
+		out = bytes.TrimSuffix(out, []byte("\n"))
+	}
+	return out, true
+}
+
+// walk menelusuri nilai JSON; path berisi key dari root (elemen array tidak menambah level).
+func (r *Redactor) walk(value any, path []string) (any, bool) {
+	switch v := value.(type) {
+	case map[string]any:
+		changed := false
+		for key, child := range v {
+			childPath := append(path[:len(path):len(path)], key)
+			if r.matchPath(childPath) {
+				v[key] = Mask
+				changed = true
+				continue
+			}
+			if redacted, ok := r.walk(child, childPath); ok {
+				v[key] = redacted
+				changed = true
+			}
+		}
+		return v, changed
+	case []any:
+		changed := false
+		for i, child := range v {
+			if redacted, ok := r.walk(child, path); ok {
+				v[i] = redacted
+				changed = true
+			}
+		}
+		return v, changed
+	case string:
+		// JSON di dalam string (mis. body yang dicatat sebagai field log) ikut diredaksi, path mulai dari root-nya
+		if trimmed := strings.TrimSpace(v); strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+			if redacted, ok := r.json([]byte(v)); ok {
+				return string(redacted), string(redacted) != v
+			}
+		}
+		redacted := r.String(v)
+		return redacted, redacted != v
+	default:
+		return value, false
+	}
+}
+
+func (r *Redactor) matchPath(path []string) bool {
+	for _, rule := range r.paths {
+		if matchSegments(rule, path) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchSegments(rule, path []string) bool {
+	if len(rule) == 0 {
+		return len(path) == 0
+	}
+	if rule[0] == "**" {
+		for i := 0; i <= len(path); i++ {
+			if matchSegments(rule[1:], path[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(path) == 0 {
+		return false
+	}
+	if rule[0] != "*" && !strings.EqualFold(rule[0], path[0]) {
+		return false
+	}
+	return matchSegments(rule[1:], path[1:])
+}
+
+// redactCredential menyembunyikan kredensial tapi tetap menyimpan skemanya
+// ("Bearer xxxxx" → "Bearer ***redacted***").
+func redactCredential(value string) string {
+	if scheme, _, ok := strings.Cut(value, " "); ok && scheme != "" {
+		return scheme + " " + Mask
+	}
+	return Mask
+}
+
+// cardNumber: angka lolos Luhn dan berdiri sendiri. Potongan dari token seperti UUID
+// ("12345678-1234-0002-...") tidak dianggap nomor kartu walau digit-nya kebetulan lolos Luhn.
+func cardNumber(s string, start, end int) bool {
+	if start >= 2 && s[start-1] == '-' && isAlnum(s[start-2]) {
+		return false
+	}
+	if end+1 < len(s) && s[end] == '-' && isAlnum(s[end+1]) {
+		return false
+	}
+	return luhn(s[start:end])
+}
+
+func isAlnum(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// luhn memvalidasi checksum nomor kartu; spasi & tanda hubung diabaikan.
+func luhn(number string) bool {
+	sum, double, digits := 0, false, 0
+	for i := len(number) - 1; i >= 0; i-- {
+		c := number[i]
+		if c == ' ' || c == '-' {
+			continue
+		}
+		d := int(c - '0')
+		if double {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+		digits++
+	}
+	return digits >= 13 && sum%10 == 0
+}
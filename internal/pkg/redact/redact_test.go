@@ -0,0 +1,224 @@
+package redact
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func TestHeader(t *testing.T) {
+	r, err := New(Config{Headers: []string{"x-signature"}})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		key   string
+		value string
+		want  string
+	}{
+		{"bearer keeps scheme", "Authorization", "Bearer eyJhbGciOi.secret", "Bearer " + Mask},
+		{"basic keeps scheme", "Authorization", "Basic dXNlcjpwYXNz", "Basic " + Mask},
+		{"credential without scheme", "Authorization", "raw-token", Mask},
+		{"proxy authorization", "Proxy-Authorization", "Bearer abc", "Bearer " + Mask},
+		{"default header", "X-Api-Key", "sk_live_123", Mask},
+		{"custom header case-insensitive", "X-SIGNATURE", "deadbeef", Mask},
+		{"pattern on other header", "X-Customer", "jane@example.com", Mask},
+		{"untouched header", "Content-Type", "application/json", "application/json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := http.Header{}
+			h.Set(tt.key, tt.value)
+			got := r.Header(h).Get(tt.key)
+			if got != tt.want {
+				t.Errorf("Header(%s: %q) = %q, want %q", tt.key, tt.value, got, tt.want)
+			}
+			if h.Get(tt.key) != tt.value {
+				t.Errorf("original header modified: %q", h.Get(tt.key))
+			}
+		})
+	}
+
+	if got := r.Header(nil); got == nil || len(got) != 0 {
+		t.Errorf("Header(nil) = %v, want empty header", got)
+	}
+}
+
+func TestBodyJSONPaths(t *testing.T) {
+	r, err := New(Config{JSONPaths: []string{"data.*.secret", "meta.token"}})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"top-level via **", `{"email":"a"}`, `{"email":"` + Mask + `"}`},
+		{"nested via **", `{"a":{"b":{"cvv":"123"}}}`, `{"a":{"b":{"cvv":"` + Mask + `"}}}`},
+		{"array element adds no level", `{"items":[{"password":"x"},{"id":1}]}`, `{"items":[{"password":"` + Mask + `"},{"id":1}]}`},
+		{"* matches exactly one level", `{"data":{"x":{"secret":"s"}}}`, `{"data":{"x":{"secret":"` + Mask + `"}}}`},
+		{"* does not match zero levels", `{"data":{"secret":"s"}}`, `{"data":{"secret":"s"}}`},
+		{"* does not match two levels", `{"data":{"x":{"y":{"secret":"s"}}}}`, `{"data":{"x":{"y":{"secret":"s"}}}}`},
+		{"exact path only from root", `{"other":{"meta":{"token":"t"}}}`, `{"other":{"meta":{"token":"t"}}}`},
+		{"key match is case-insensitive", `{"Card_Number":"x"}`, `{"Card_Number":"` + Mask + `"}`},
+		{"whole object replaced", `{"card_number":{"pan":"x"}}`, `{"card_number":"` + Mask + `"}`},
+		{"numbers preserved", `{"amount":12345678901234567890}`, `{"amount":12345678901234567890}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(r.Body([]byte(tt.body))); got != tt.want {
+				t.Errorf("Body(%s) = %s, want %s", tt.body, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBodyNestedJSONString(t *testing.T) {
+	r := Default()
+	inner := `{"card_number":"4111111111111111","status":"PAID"}`
+	outer, _ := json.Marshal(map[string]string{"body": inner})
+
+	got := r.Body(outer)
+	var decoded map[string]string
+	if err := json.Unmarshal(got, &decoded); err != nil {
+		t.Fatalf("result is not JSON: %s", got)
+	}
+	want := `{"card_number":"` + Mask + `","status":"PAID"}`
+	if decoded["body"] != want {
+		t.Errorf("nested body = %s, want %s", decoded["body"], want)
+	}
+}
+
+func TestBodyPlainText(t *testing.T) {
+	r := Default()
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"email in text", "contact jane@example.com now", "contact " + Mask + " now"},
+		{"no match returns body", "status=PENDING", "status=PENDING"},
+		{"invalid JSON falls back to text", `{"email": jane@example.com`, `{"email": ` + Mask},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(r.Body([]byte(tt.body))); got != tt.want {
+				t.Errorf("Body(%q) = %q, want %q", tt.body, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStringCardNumbers(t *testing.T) {
+	r := Default()
+
+	// Digit run pertama UUID ini (12345678-1234-0002) kebetulan lolos Luhn
+	uuidLuhn := "12345678-1234-0002-8abc-def012345678"
+	if !luhn("1234567812340002") {
+		t.Fatal("test UUID digit run must pass Luhn")
+	}
+	// Digit run di ujung UUID (1234-567890120003) juga lolos Luhn
+	uuidTail := "abcdefab-cdef-abcd-1234-567890120003"
+	if !luhn("1234567890120003") {
+		t.Fatal("test UUID tail digit run must pass Luhn")
+	}
+
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"valid card", "card 4111111111111111 ok", "card " + Mask + " ok"},
+		{"valid card with spaces", "4111 1111 1111 1111", Mask},
+		{"valid card with dashes", "pan=4111-1111-1111-1111;", "pan=" + Mask + ";"},
+		{"luhn failure kept", "order 4111111111111112", "order 4111111111111112"},
+		{"too short kept", "411111111111", "411111111111"},
+		{"uuid with luhn-valid prefix kept", uuidLuhn, uuidLuhn},
+		{"uuid with luhn-valid tail kept", uuidTail, uuidTail},
+		{"uuid in url kept", "/api/v1/payments/" + uuidLuhn, "/api/v1/payments/" + uuidLuhn},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := r.String(tt.in); got != tt.want {
+				t.Errorf("String(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewInvalidPattern(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns map[string]string
+		wantErr  bool
+	}{
+		{"valid custom pattern", map[string]string{"nik": `\b\d{16}\b`}, false},
+		{"unbalanced paren", map[string]string{"bad": `(abc`}, true},
+		{"invalid repetition", map[string]string{"bad": `a**`}, true},
+		{"override default with invalid", map[string]string{"email": `[`}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(Config{Patterns: tt.patterns})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCustomPattern(t *testing.T) {
+	r, err := New(Config{Patterns: map[string]string{"nik": `\bNIK-\d{6}\b`}})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if got := r.String("user NIK-123456"); got != "user "+Mask {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestWriterZerolog(t *testing.T) {
+	var buf bytes.Buffer
+	logger := zerolog.New(NewWriter(Default(), &buf))
+
+	logger.Info().
+		Str("card_number", "4111111111111111").
+		Str("payment_id", "12345678-1234-0002-8abc-def012345678").
+		Bytes("body", []byte(`{"password":"hunter2","status":"PAID"}`)).
+		Msg("paid by jane@example.com")
+
+	line := buf.String()
+	if !strings.HasSuffix(line, "\n") {
+		t.Errorf("log line must keep trailing newline: %q", line)
+	}
+	for _, secret := range []string{"4111111111111111", "hunter2", "jane@example.com"} {
+		if strings.Contains(line, secret) {
+			t.Errorf("log line leaks %q: %s", secret, line)
+		}
+	}
+
+	var entry map[string]any
+	if err := json.Unmarshal([]byte(line), &entry); err != nil {
+		t.Fatalf("log line is not JSON: %v (%s)", err, line)
+	}
+	if entry["card_number"] != Mask {
+		t.Errorf("card_number = %v", entry["card_number"])
+	}
+	if entry["payment_id"] != "12345678-1234-0002-8abc-def012345678" {
+		t.Errorf("payment_id = %v", entry["payment_id"])
+	}
+	if entry["message"] != "paid by "+Mask {
+		t.Errorf("message = %v", entry["message"])
+	}
+	if body, _ := entry["body"].(string); !strings.Contains(body, `"password":"`+Mask+`"`) || !strings.Contains(body, `"status":"PAID"`) {
+		t.Errorf("body = %v", entry["body"])
+	}
+}
@@ -0,0 +1,23 @@
+package redact
+
+import (
+	"io"
+)
+
+// Writer membungkus output zerolog (console, telemetry) supaya setiap baris log diredaksi sebelum ditulis.
+type Writer struct {
+	redactor *Redactor
+	out      io.Writer
+}
+
+func NewWriter(redactor *Redactor, out io.Writer) *Writer {
+	return &Writer{redactor: redactor, out: out}
+}
+
+// Write mengembalikan len(p) walau isi yang diteruskan berubah panjang, sesuai kontrak io.Writer untuk pemanggil.
+func (w *Writer) Write(p []byte) (int, error) {
+	if _, err := w.out.Write(w.redactor.Body(p)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,277 @@
+package scheduler
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"github.com/rs/zerolog"
+	"sync"
+	"time"
+)
+
+var ErrQueueFull = errors.New("scheduler queue is full")
+
+// Job dijalankan worker saat sebuah key jatuh tempo.
+// Kembalikan delay sampai run berikutnya, atau done=true untuk berhenti menjadwalkan key tsb.
+type Job func(ctx context.Context, key string) (next time.Duration, done bool)
+
+type item struct {
+	key     string
+	dueAt   time.Time
+	index   int  // posisi di heap, -1 jika tidak di heap
+	running bool // sedang dijalankan worker
+	woken   bool // di-Wake saat running → jalankan lagi segera setelah selesai
+	removed bool // di-Remove saat running → jangan dijadwalkan ulang
+}
+
+// Scheduler menyimpan jadwal di min-heap (berdasarkan dueAt) dan menjalankan job
+// dengan worker pool berukuran tetap, jadi jumlah goroutine tidak ikut naik
+// seiring jumlah task.
+type Scheduler struct {
+	mu       sync.Mutex
+	items    map[string]*item
+	queue    itemHeap
+	notify   chan struct{} // bangunkan dispatcher saat head heap berubah
+	ready    chan *item
+	workers  int
+	maxQueue int
+	job      Job
+	logger   zerolog.Logger
+	wg       sync.WaitGroup
+	cancel   context.CancelFunc
+}
+
+func NewScheduler(workers int, maxQueue int, logger zerolog.Logger) *Scheduler {
+	if workers <= 0 {
+		workers = 1
+	}
+	return &Scheduler{
+		items:    make(map[string]*item),
+		notify:   make(chan struct{}, 1),
+		ready:    make(chan *item),
+		workers:  workers,
+		maxQueue: maxQueue,
+		logger:   logger,
+	}
+}
+
+// Start menjalankan dispatcher + worker pool sampai ctx selesai atau Stop dipanggil.
+func (s *Scheduler) Start(ctx context.Context, job Job) {
+	ctx, cancel := context.WithCancel(ctx)
+	s.job = job
+	s.cancel = cancel
+
+	s.wg.Add(1)
+	go s.dispatch(ctx)
+	for i := 0; i < s.workers; i++ {
+		s.wg.Add(1)
+		go s.work(ctx)
+	}
+	s.logger.Info().Msgf("⏱️ Scheduler started with %d workers (queue limit %d)", s.workers, s.maxQueue)
+}
+
+// Stop menghentikan dispatcher dan menunggu semua worker yang sedang jalan selesai.
+func (s *Scheduler) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
+}
+
+// Schedule menambahkan key baru (atau menjadwalkan ulang key yang sudah ada) untuk jalan setelah delay.
+func (s *Scheduler) Schedule(key string, delay time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dueAt := time.Now().Add(delay)
+	if it, ok := s.items[key]; ok {
+		switch {
+		case it.running:
+			it.removed = false
+			if delay <= 0 {
+				it.woken = true
+			}
+		case it.index >= 0:
+			it.dueAt = dueAt
+			heap.Fix(&s.queue, it.index)
+			s.signal()
+		}
+		return nil
+	}
+
+	if s.maxQueue > 0 && len(s.items) >= s.maxQueue {
+		return ErrQueueFull
+	}
+
+	it := &item{key: key, dueAt: dueAt, index: -1}
+	s.items[key] = it
+	heap.Push(&s.queue, it)
+	s.signal()
+	return nil
+}
+
+// Wake memajukan jadwal key ke sekarang. Return false jika key tidak terdaftar.
+func (s *Scheduler) Wake(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	it, ok := s.items[key]
+	if !ok || it.removed {
+		return false
+	}
+	if it.running {
+		it.woken = true
+		return true
+	}
+	it.dueAt = time.Now()
+	heap.Fix(&s.queue, it.index)
+	s.signal()
+	return true
+}
+
+// Remove menghapus key dari jadwal. Jika sedang jalan, key tidak akan dijadwalkan ulang.
+func (s *Scheduler) Remove(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	it, ok := s.items[key]
+	if !ok {
+		return
+	}
+	if it.running {
+		it.removed = true
+		return
+	}
+	heap.Remove(&s.queue, it.index)
+	delete(s.items, key)
+	s.signal()
+}
+
+// Len jumlah key yang terdaftar (menunggu + sedang jalan).
+func (s *Scheduler) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.items)
+}
+
+func (s *Scheduler) signal() {
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (s *Scheduler) dispatch(ctx context.Context) {
+	defer s.wg.Done()
+
+	timer := time.NewTimer(time.Hour)
+	defer timer.Stop()
+
+	for {
+		// Ambil semua item yang sudah jatuh tempo
+		var due []*item
+		wait := time.Hour
+		now := time.Now()
+
+		s.mu.Lock()
+		for s.queue.Len() > 0 {
+			head := s.queue[0]
+			if head.dueAt.After(now) {
+				wait = head.dueAt.Sub(now)
+				break
+			}
+			heap.Pop(&s.queue)
+			head.running = true
+			due = append(due, head)
+		}
+		s.mu.Unlock()
+
+		// Serahkan ke worker; blok jika semua worker sibuk (backpressure)
+		for _, it := range due {
+			select {
+			case s.ready <- it:
+			case <-ctx.Done():
+				return
+			}
+		}
+		if len(due) > 0 {
+			continue
+		}
+
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(wait)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.notify:
+		case <-timer.C:
+		}
+	}
+}
+
+func (s *Scheduler) work(ctx context.Context) {
+	defer s.wg.Done()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case it := <-s.ready:
+			next, done := s.job(ctx, it.key)
+			s.finish(it, next, done)
+		}
+	}
+}
+
+func (s *Scheduler) finish(it *item, next time.Duration, done bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	it.running = false
+	if done || it.removed {
+		if s.items[it.key] == it {
+			delete(s.items, it.key)
+		}
+		return
+	}
+	if it.woken {
+		it.woken = false
+		next = 0
+	}
+	it.dueAt = time.Now().Add(next)
+	heap.Push(&s.queue, it)
+	s.signal()
+}
+
+// itemHeap adalah min-heap berdasarkan dueAt (container/heap).
+type itemHeap []*item
+
+func (h itemHeap) Len() int           { return len(h) }
+func (h itemHeap) Less(i, j int) bool { return h[i].dueAt.Before(h[j].dueAt) }
+func (h itemHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *itemHeap) Push(x any) {
+	it := x.(*item)
+	it.index = len(*h)
+	*h = append(*h, it)
+}
+
+func (h *itemHeap) Pop() any {
+	old := *h
+	n := len(old)
+	it := old[n-1]
+	old[n-1] = nil
+	it.index = -1
+	*h = old[:n-1]
+	return it
+}
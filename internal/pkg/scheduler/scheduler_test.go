@@ -0,0 +1,352 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+const waitTimeout = 2 * time.Second
+
+func newTestScheduler(t *testing.T, workers, maxQueue int, job Job) *Scheduler {
+	t.Helper()
+	s := NewScheduler(workers, maxQueue, zerolog.Nop())
+	s.Start(context.Background(), job)
+	t.Cleanup(s.Stop)
+	return s
+}
+
+func receive(t *testing.T, ch <-chan string, what string) string {
+	t.Helper()
+	select {
+	case key := <-ch:
+		return key
+	case <-time.After(waitTimeout):
+		t.Fatalf("timeout waiting for %s", what)
+		return ""
+	}
+}
+
+func expectNone(t *testing.T, ch <-chan string, within time.Duration, what string) {
+	t.Helper()
+	select {
+	case key := <-ch:
+		t.Fatalf("unexpected %s: %s", what, key)
+	case <-time.After(within):
+	}
+}
+
+func TestScheduleRunsInDueOrder(t *testing.T) {
+	runs := make(chan string, 10)
+	s := newTestScheduler(t, 1, 0, func(_ context.Context, key string) (time.Duration, bool) {
+		runs <- key
+		return 0, true
+	})
+
+	delays := map[string]time.Duration{"c": 60 * time.Millisecond, "a": 20 * time.Millisecond, "b": 40 * time.Millisecond}
+	for _, key := range []string{"c", "a", "b"} {
+		if err := s.Schedule(key, delays[key]); err != nil {
+			t.Fatalf("Schedule(%s): %v", key, err)
+		}
+	}
+
+	for _, want := range []string{"a", "b", "c"} {
+		if got := receive(t, runs, "run "+want); got != want {
+			t.Fatalf("run order: got %s, want %s", got, want)
+		}
+	}
+}
+
+func TestScheduleExistingKeyReschedules(t *testing.T) {
+	runs := make(chan string, 10)
+	s := newTestScheduler(t, 1, 0, func(_ context.Context, key string) (time.Duration, bool) {
+		runs <- key
+		return 0, true
+	})
+
+	if err := s.Schedule("k", time.Hour); err != nil {
+		t.Fatalf("Schedule: %v", err)
+	}
+	if err := s.Schedule("k", 0); err != nil {
+		t.Fatalf("re-Schedule: %v", err)
+	}
+	receive(t, runs, "rescheduled run")
+	if s.Len() != 0 {
+		t.Errorf("Len() = %d after done, want 0", s.Len())
+	}
+}
+
+func TestJobNextDelayReschedules(t *testing.T) {
+	var count atomic.Int32
+	runs := make(chan string, 10)
+	s := newTestScheduler(t, 1, 0, func(_ context.Context, key string) (time.Duration, bool) {
+		runs <- key
+		return time.Millisecond, count.Add(1) == 3
+	})
+	if err := s.Schedule("k", 0); err != nil {
+		t.Fatalf("Schedule: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		receive(t, runs, fmt.Sprintf("run %d", i+1))
+	}
+	expectNone(t, runs, 50*time.Millisecond, "run after done")
+}
+
+func TestWakeIdleKey(t *testing.T) {
+	runs := make(chan string, 10)
+	s := newTestScheduler(t, 1, 0, func(_ context.Context, key string) (time.Duration, bool) {
+		runs <- key
+		return 0, true
+	})
+
+	if s.Wake("missing") {
+		t.Error("Wake(missing) = true, want false")
+	}
+	if err := s.Schedule("k", time.Hour); err != nil {
+		t.Fatalf("Schedule: %v", err)
+	}
+	if !s.Wake("k") {
+		t.Fatal("Wake(k) = false, want true")
+	}
+	receive(t, runs, "woken run")
+}
+
+func TestWakeWhileRunningRunsAgainImmediately(t *testing.T) {
+	started := make(chan string, 10)
+	release := make(chan struct{})
+	var count atomic.Int32
+	s := newTestScheduler(t, 1, 0, func(_ context.Context, key string) (time.Duration, bool) {
+		n := count.Add(1)
+		started <- key
+		if n == 1 {
+			<-release
+			return time.Hour, false // tanpa Wake, run berikutnya baru satu jam lagi
+		}
+		return 0, true
+	})
+
+	if err := s.Schedule("k", 0); err != nil {
+		t.Fatalf("Schedule: %v", err)
+	}
+	receive(t, started, "first run")
+	if !s.Wake("k") {
+		t.Fatal("Wake while running = false, want true")
+	}
+	close(release)
+	receive(t, started, "run after wake")
+}
+
+func TestRemovePendingKey(t *testing.T) {
+	runs := make(chan string, 10)
+	s := newTestScheduler(t, 1, 0, func(_ context.Context, key string) (time.Duration, bool) {
+		runs <- key
+		return 0, true
+	})
+
+	if err := s.Schedule("k", 30*time.Millisecond); err != nil {
+		t.Fatalf("Schedule: %v", err)
+	}
+	s.Remove("k")
+	s.Remove("missing")
+	if s.Len() != 0 {
+		t.Errorf("Len() = %d, want 0", s.Len())
+	}
+	if s.Wake("k") {
+		t.Error("Wake after Remove = true, want false")
+	}
+	expectNone(t, runs, 80*time.Millisecond, "run of removed key")
+}
+
+func TestRemoveDuringRunIsNotRescheduled(t *testing.T) {
+	started := make(chan string, 10)
+	release := make(chan struct{})
+	s := newTestScheduler(t, 1, 0, func(_ context.Context, key string) (time.Duration, bool) {
+		started <- key
+		<-release
+		return 0, false // minta jalan lagi segera; Remove harus menang
+	})
+
+	if err := s.Schedule("k", 0); err != nil {
+		t.Fatalf("Schedule: %v", err)
+	}
+	receive(t, started, "first run")
+	s.Remove("k")
+	if s.Wake("k") {
+		t.Error("Wake of key removed during run = true, want false")
+	}
+	close(release)
+
+	expectNone(t, started, 80*time.Millisecond, "run after Remove")
+	if s.Len() != 0 {
+		t.Errorf("Len() = %d, want 0", s.Len())
+	}
+}
+
+func TestScheduleAfterRemoveDuringRunKeepsKey(t *testing.T) {
+	started := make(chan string, 10)
+	release := make(chan struct{})
+	var count atomic.Int32
+	s := newTestScheduler(t, 1, 0, func(_ context.Context, key string) (time.Duration, bool) {
+		n := count.Add(1)
+		started <- key
+		if n == 1 {
+			<-release
+			return 0, false
+		}
+		return 0, true
+	})
+
+	if err := s.Schedule("k", 0); err != nil {
+		t.Fatalf("Schedule: %v", err)
+	}
+	receive(t, started, "first run")
+	s.Remove("k")
+	if err := s.Schedule("k", 0); err != nil {
+		t.Fatalf("Schedule after Remove: %v", err)
+	}
+	close(release)
+	receive(t, started, "run after re-Schedule")
+}
+
+func TestQueueFull(t *testing.T) {
+	runs := make(chan string, 10)
+	s := newTestScheduler(t, 1, 2, func(_ context.Context, key string) (time.Duration, bool) {
+		runs <- key
+		return 0, true
+	})
+
+	for _, key := range []string{"a", "b"} {
+		if err := s.Schedule(key, time.Hour); err != nil {
+			t.Fatalf("Schedule(%s): %v", key, err)
+		}
+	}
+	if err := s.Schedule("c", time.Hour); !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("Schedule(c) error = %v, want ErrQueueFull", err)
+	}
+	// Key yang sudah terdaftar tetap boleh dijadwalkan ulang walau antrean penuh
+	if err := s.Schedule("a", 0); err != nil {
+		t.Fatalf("re-Schedule(a) on full queue: %v", err)
+	}
+	receive(t, runs, "run a")
+
+	// Slot a kosong setelah done → key baru diterima lagi
+	deadline := time.Now().Add(waitTimeout)
+	for s.Len() != 1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Len() = %d, want 1", s.Len())
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if err := s.Schedule("c", time.Hour); err != nil {
+		t.Fatalf("Schedule(c) after slot freed: %v", err)
+	}
+}
+
+func TestStopWaitsForRunningJob(t *testing.T) {
+	started := make(chan string, 1)
+	var finished atomic.Bool
+	s := NewScheduler(2, 0, zerolog.Nop())
+	s.Start(context.Background(), func(ctx context.Context, key string) (time.Duration, bool) {
+		started <- key
+		<-ctx.Done() // job melihat ctx dibatalkan saat Stop
+		time.Sleep(20 * time.Millisecond)
+		finished.Store(true)
+		return 0, false
+	})
+
+	if err := s.Schedule("k", 0); err != nil {
+		t.Fatalf("Schedule: %v", err)
+	}
+	if err := s.Schedule("later", time.Hour); err != nil {
+		t.Fatalf("Schedule: %v", err)
+	}
+	receive(t, started, "run")
+
+	stopped := make(chan struct{})
+	go func() {
+		s.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(waitTimeout):
+		t.Fatal("Stop did not return")
+	}
+	if !finished.Load() {
+		t.Error("Stop returned before running job finished")
+	}
+
+	// Setelah Stop tidak ada lagi job yang dijalankan
+	if !s.Wake("later") {
+		t.Error("Wake(later) = false, want true (key still registered)")
+	}
+	expectNone(t, started, 50*time.Millisecond, "run after Stop")
+}
+
+func TestStopOnContextCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := NewScheduler(1, 0, zerolog.Nop())
+	s.Start(ctx, func(context.Context, string) (time.Duration, bool) { return 0, true })
+	cancel()
+
+	stopped := make(chan struct{})
+	go func() {
+		s.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(waitTimeout):
+		t.Fatal("Stop did not return after ctx cancel")
+	}
+}
+
+func TestConcurrentScheduleWakeRemove(t *testing.T) {
+	const keys = 50
+	var mu sync.Mutex
+	counts := map[string]int{}
+	s := newTestScheduler(t, 4, 0, func(_ context.Context, key string) (time.Duration, bool) {
+		mu.Lock()
+		counts[key]++
+		n := counts[key]
+		mu.Unlock()
+		return time.Millisecond, n >= 3
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < keys; i++ {
+		key := fmt.Sprintf("k%d", i)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := s.Schedule(key, time.Millisecond); err != nil {
+				t.Errorf("Schedule(%s): %v", key, err)
+			}
+			s.Wake(key)
+			s.Schedule(key, 0)
+		}()
+	}
+	wg.Wait()
+
+	deadline := time.Now().Add(waitTimeout)
+	for s.Len() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Len() = %d, want 0", s.Len())
+		}
+		time.Sleep(time.Millisecond)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	for i := 0; i < keys; i++ {
+		if key := fmt.Sprintf("k%d", i); counts[key] != 3 {
+			t.Errorf("%s ran %d times, want 3", key, counts[key])
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package repository
+
+import (
+	"beta-payment-api-client/internal/entity"
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/lib/pq"
+	"github.com/rs/zerolog"
+	"time"
+)
+
+const (
+	checkLogTable            = "payment_record_check_logs"
+	checkLogDefaultPartition = "payment_record_check_logs_default"
+	// checkLogRetentionLockKey advisory lock supaya job retention hanya jalan di satu replica
+	checkLogRetentionLockKey int64 = 2025082610
+)
+
+type PaymentRecordCheckLogPartitionRepository interface {
+	TryLock(ctx context.Context) (unlock func(), ok bool, err error)
+	ListPartitions(ctx context.Context) ([]entity.CheckLogPartition, error)
+	EnsurePartition(ctx context.Context, partition entity.CheckLogPartition) (bool, error)
+	ArchiveLastAttempts(ctx context.Context, partition entity.CheckLogPartition) (int64, error)
+	RemovePartition(ctx context.Context, partition entity.CheckLogPartition, detach bool) error
+	PurgeDefaultPartition(ctx context.Context, before time.Time) (int64, error)
+	PurgeArchives(ctx context.Context, before time.Time) (int64, error)
+}
+
+type paymentRecordCheckLogPartitionRepo struct {
+	DB     *sql.DB
+	logger zerolog.Logger
+}
+
+func NewPaymentRecordCheckLogPartitionRepository(
+	db *sql.DB,
+	logger zerolog.Logger) PaymentRecordCheckLogPartitionRepository {
+	return &paymentRecordCheckLogPartitionRepo{
+		DB:     db,
+		logger: logger,
+	}
+}
+
+// TryLock mengambil advisory lock session-level pada satu koneksi khusus;
+// ok=false kalau lock sedang dipegang replica lain.
+func (p *paymentRecordCheckLogPartitionRepo) TryLock(ctx context.Context) (func(), bool, error) {
+	conn, err := p.DB.Conn(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var locked bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", checkLogRetentionLockKey).Scan(&locked); err != nil {
+		_ = conn.Close()
+		return nil, false, err
+	}
+	if !locked {
+		_ = conn.Close()
+		return nil, false, nil
+	}
+
+	unlock := func() {
+		if _, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", checkLogRetentionLockKey); err != nil {
+			p.logger.Warn().Err(err).Msg("‼️ Failed to release check log retention lock")
+		}
+		_ = conn.Close()
+	}
+	return unlock, true, nil
+}
+
+// ListPartitions mengembalikan partisi bulanan yang masih ter-attach, terurut dari yang paling lama.
+func (p *paymentRecordCheckLogPartitionRepo) ListPartitions(ctx context.Context) ([]entity.CheckLogPartition, error) {
+	rows, err := p.DB.QueryContext(ctx,
+		"SELECT c.relname FROM pg_inherits i JOIN pg_class c ON c.oid = i.inhrelid "+
+			"WHERE i.inhparent = $1::regclass ORDER BY c.relname", checkLogTable)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var partitions []entity.CheckLogPartition
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		if partition, ok := entity.ParseCheckLogPartition(name); ok {
+			partitions = append(partitions, partition)
+		}
+	}
+	return partitions, rows.Err()
+}
+
+// EnsurePartition membuat partisi kalau belum ada; created=false kalau sudah ada.
+// Row yang terlanjur masuk partisi default untuk bulan tersebut dipindahkan ke partisi baru,
+// karena PostgreSQL menolak CREATE PARTITION selama default masih memuat row di rentangnya.
+func (p *paymentRecordCheckLogPartitionRepo) EnsurePartition(ctx context.Context, partition entity.CheckLogPartition) (bool, error) {
+	var exists bool
+	if err := p.DB.QueryRowContext(ctx, "SELECT to_regclass($1) IS NOT NULL", partition.Name).Scan(&exists); err != nil {
+		return false, err
+	}
+	if exists {
+		return false, nil
+	}
+
+	from, to := partition.From.Format("2006-01-02"), partition.To.Format("2006-01-02")
+	createPartition := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s PARTITION OF %s FOR VALUES FROM (%s) TO (%s)",
+		pq.QuoteIdentifier(partition.Name), checkLogTable, pq.QuoteLiteral(from), pq.QuoteLiteral(to))
+
+	var stranded bool
+	if err := p.DB.QueryRowContext(ctx,
+		"SELECT EXISTS (SELECT 1 FROM "+checkLogDefaultPartition+" WHERE occurred_at >= $1 AND occurred_at < $2)",
+		partition.From, partition.To).Scan(&stranded); err != nil {
+		return false, err
+	}
+	if !stranded {
+		if _, err := p.DB.ExecContext(ctx, createPartition); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	statements := []string{
+		"ALTER TABLE " + checkLogTable + " DETACH PARTITION " + checkLogDefaultPartition,
+		createPartition,
+		fmt.Sprintf("INSERT INTO %s SELECT * FROM %s WHERE occurred_at >= %s AND occurred_at < %s",
+			pq.QuoteIdentifier(partition.Name), checkLogDefaultPartition, pq.QuoteLiteral(from), pq.QuoteLiteral(to)),
+		fmt.Sprintf("DELETE FROM %s WHERE occurred_at >= %s AND occurred_at < %s",
+			checkLogDefaultPartition, pq.QuoteLiteral(from), pq.QuoteLiteral(to)),
+		"ALTER TABLE " + checkLogTable + " ATTACH PARTITION " + checkLogDefaultPartition + " DEFAULT",
+	}
+	for _, statement := range statements {
+		if _, err := tx.ExecContext(ctx, statement); err != nil {
+			return false, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+	p.logger.Info().Msgf("📦 Moved stranded check logs from default partition into %s", partition.Name)
+	return true, nil
+}
+
+// ArchiveLastAttempts menyalin percobaan terakhir payment final di partisi ini ke payment_record_check_log_archives.
+// Archive yang sudah berisi percobaan lebih baru tidak ditimpa.
+func (p *paymentRecordCheckLogPartitionRepo) ArchiveLastAttempts(ctx context.Context, partition entity.CheckLogPartition) (int64, error) {
+	finals := make([]string, 0)
+	for _, status := range entity.FinalPaymentStatuses() {
+		finals = append(finals, string(status))
+	}
+
+	result, err := p.DB.ExecContext(ctx,
+		"INSERT INTO payment_record_check_log_archives ("+
+			"payment_id, check_log_id, final_status, occurred_at, method, url, request_headers, request_body, "+
+			"response_headers, response_body, status_code, delay_seconds) "+
+			"SELECT DISTINCT ON (l.payment_id) l.payment_id, l.id, r.status, l.occurred_at, l.method, l.url, "+
+			"l.request_headers, l.request_body, l.response_headers, l.response_body, l.status_code, l.delay_seconds "+
+			"FROM "+pq.QuoteIdentifier(partition.Name)+" l JOIN payment_records r ON r.id = l.payment_id "+
+			"WHERE r.status = ANY($1) AND l.deleted_at IS NULL "+
+			"ORDER BY l.payment_id, l.occurred_at DESC, l.id DESC "+
+			"ON CONFLICT (payment_id) DO UPDATE SET "+
+			"check_log_id = EXCLUDED.check_log_id, final_status = EXCLUDED.final_status, occurred_at = EXCLUDED.occurred_at, "+
+			"method = EXCLUDED.method, url = EXCLUDED.url, request_headers = EXCLUDED.request_headers, "+
+			"request_body = EXCLUDED.request_body, response_headers = EXCLUDED.response_headers, "+
+			"response_body = EXCLUDED.response_body, status_code = EXCLUDED.status_code, "+
+			"delay_seconds = EXCLUDED.delay_seconds, archived_at = CURRENT_TIMESTAMP "+
+			"WHERE payment_record_check_log_archives.occurred_at < EXCLUDED.occurred_at",
+		pq.Array(finals))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// RemovePartition men-drop partisi, atau hanya men-detach (tabel tetap ada untuk diarsip manual).
+func (p *paymentRecordCheckLogPartitionRepo) RemovePartition(ctx context.Context, partition entity.CheckLogPartition, detach bool) error {
+	statement := "DROP TABLE IF EXISTS " + pq.QuoteIdentifier(partition.Name)
+	if detach {
+		statement = "ALTER TABLE " + checkLogTable + " DETACH PARTITION " + pq.QuoteIdentifier(partition.Name)
+	}
+	_, err := p.DB.ExecContext(ctx, statement)
+	return err
+}
+
+// PurgeDefaultPartition menghapus row lama yang tersangkut di partisi default.
+func (p *paymentRecordCheckLogPartitionRepo) PurgeDefaultPartition(ctx context.Context, before time.Time) (int64, error) {
+	result, err := p.DB.ExecContext(ctx, "DELETE FROM "+checkLogDefaultPartition+" WHERE occurred_at < $1", before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (p *paymentRecordCheckLogPartitionRepo) PurgeArchives(ctx context.Context, before time.Time) (int64, error) {
+	result, err := p.DB.ExecContext(ctx, "DELETE FROM payment_record_check_log_archives WHERE occurred_at < $1", before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
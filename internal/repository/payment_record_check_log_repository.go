@@ -2,31 +2,44 @@ package repository
 
 import (
 	"beta-payment-api-client/internal/entity"
+	"beta-payment-api-client/internal/pkg/redact"
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"github.com/google/uuid"
 	"github.com/rs/zerolog"
 	"net/http"
+	"strings"
 	"time"
 )
 
 type PaymentRecordCheckLogRepository interface {
 	Store(ctx context.Context, tx *sql.Tx, payment *entity.PaymentRecordCheckLog) error
+	// LogFetchAttempt memasukkan percobaan cek ke writer asynchronous; tidak menunggu INSERT selesai.
 	LogFetchAttempt(paymentRecordCheckHTTP *entity.PaymentRecordCheckHTTP, delaySeconds time.Duration) error
+	FetchByPaymentID(ctx context.Context, filter entity.CheckHistoryFilter) ([]entity.PaymentRecordCheckLog, int, error)
+	FetchByID(ctx context.Context, id uuid.UUID) (*entity.PaymentRecordCheckLog, error)
 }
 
 type paymentRecordCheckLogRepo struct {
-	DB     *sql.DB
-	logger zerolog.Logger
+	DB       *sql.DB
+	writer   *CheckLogWriter
+	redactor *redact.Redactor
+	logger   zerolog.Logger
 }
 
 func NewPaymentRecordCheckLogRepository(
 	db *sql.DB,
+	writer *CheckLogWriter,
+	redactor *redact.Redactor,
 	logger zerolog.Logger) PaymentRecordCheckLogRepository {
 	return &paymentRecordCheckLogRepo{
-		DB:     db,
-		logger: logger,
+		DB:       db,
+		writer:   writer,
+		redactor: redactor,
+		logger:   logger,
 	}
 }
 
@@ -47,8 +60,8 @@ func (p *paymentRecordCheckLogRepo) LogFetchAttempt(
 	delay time.Duration,
 ) error {
 	// Guard repo & argumen
-	if p == nil || p.DB == nil {
-		return errors.New("log repo/db is nil")
+	if p == nil || p.writer == nil {
+		return errors.New("log repo/writer is nil")
 	}
 	if paymentRecordCheckHTTP == nil {
 		return errors.New("paymentRecordCheckHTTP is nil")
@@ -63,12 +76,12 @@ func (p *paymentRecordCheckLogRepo) LogFetchAttempt(
 	if paymentRecordCheckHTTP.Request != nil {
 		method = paymentRecordCheckHTTP.Request.Method
 		if paymentRecordCheckHTTP.Request.URL != nil {
-			urlStr = paymentRecordCheckHTTP.Request.URL.String()
+			urlStr = p.redactor.String(paymentRecordCheckHTTP.Request.URL.String())
 		}
 		reqHeaders = paymentRecordCheckHTTP.Request.Header // http.Header (map) — boleh nil
 	}
 
-	reqHeadersRedacted := redactHeaders(reqHeaders) // aman walau nil
+	reqHeadersRedacted := p.redactor.Header(reqHeaders) // aman walau nil
 	reqHeadersJSON, _ := marshalHeaders(reqHeadersRedacted)
 
 	// GET → biasanya tidak ada body; kalau mau capture POST/PUT nanti pakai req.GetBody()
@@ -83,9 +96,12 @@ func (p *paymentRecordCheckLogRepo) LogFetchAttempt(
 		respHeaders = paymentRecordCheckHTTP.Response.Header // boleh nil
 	}
 	// Walau ResponseBody nil/non-nil, header tetap kita simpan apa adanya (bisa kosong)
-	respHeadersRedacted := redactHeaders(respHeaders)
+	respHeadersRedacted := p.redactor.Header(respHeaders)
 	respHeadersJSON, _ = marshalHeaders(respHeadersRedacted)
 
+	// Body disimpan setelah diredaksi (JSON path + pattern), bukan verbatim
+	respBody := p.redactor.Body(paymentRecordCheckHTTP.ResponseBody)
+
 	// ===== Delay detik (bukan nanoseconds) =====
 	delaySeconds := int64(delay.Seconds())
 	if delaySeconds < 0 {
@@ -93,43 +109,121 @@ func (p *paymentRecordCheckLogRepo) LogFetchAttempt(
 	}
 
 	// ===== Bangun row =====
+	// occurred_at diisi sekarang, bukan saat batch di-flush. Satu sumber waktu (jam aplikasi, UTC) untuk
+	// occurred_at / created_at / updated_at: kolom TIMESTAMP tanpa zona, partisi & retention juga dihitung dalam UTC.
+	occurredAt := time.Now().UTC()
 	logRow := entity.PaymentRecordCheckLog{
 		ID:              uuid.New(),
 		PaymentID:       paymentRecordCheckHTTP.ID,
+		OccurredAt:      &occurredAt,
 		Method:          method,
 		URL:             urlStr,
 		RequestHeaders:  reqHeadersJSON,
 		RequestBody:     reqBody,
 		ResponseHeaders: respHeadersJSON,
-		ResponseBody:    paymentRecordCheckHTTP.ResponseBody, // boleh nil
-		StatusCode:      paymentRecordCheckHTTP.StatusCode,   // 0 jika belum ada resp
+		ResponseBody:    respBody,                          // boleh nil
+		StatusCode:      paymentRecordCheckHTTP.StatusCode, // 0 jika belum ada resp
 		DelaySeconds:    delaySeconds,
+		CreatedAt:       &occurredAt,
+		UpdatedAt:       &occurredAt,
 	}
 
-	// ===== Transaksi (pakai context yang ada) =====
+	// ===== Antri ke writer asynchronous (pakai context yang ada untuk policy block) =====
 	ctx := context.Background()
 	if paymentRecordCheckHTTP.Context != nil {
 		ctx = paymentRecordCheckHTTP.Context
 	}
+	return p.writer.Enqueue(ctx, logRow)
+}
 
-	tx, err := p.DB.Begin()
+// FetchByID mengambil satu row check log; sql.ErrNoRows kalau tidak ada (atau partisinya sudah dibuang).
+func (p *paymentRecordCheckLogRepo) FetchByID(ctx context.Context, id uuid.UUID) (*entity.PaymentRecordCheckLog, error) {
+	var (
+		row             entity.PaymentRecordCheckLog
+		requestHeaders  []byte
+		responseHeaders []byte
+	)
+	err := p.DB.QueryRowContext(ctx,
+		"SELECT id, payment_id, occurred_at, method, url, request_headers, request_body, "+
+			"response_headers, response_body, COALESCE(status_code, 0), delay_seconds, created_at, updated_at "+
+			"FROM payment_record_check_logs WHERE id = $1 AND deleted_at IS NULL", id).
+		Scan(&row.ID, &row.PaymentID, &row.OccurredAt, &row.Method, &row.URL, &requestHeaders, &row.RequestBody,
+			&responseHeaders, &row.ResponseBody, &row.StatusCode, &row.DelaySeconds, &row.CreatedAt, &row.UpdatedAt)
 	if err != nil {
-		p.logger.Error().Err(err).Msg("❌ Failed to begin transaction")
-		return err
+		return nil, err
+	}
+	row.RequestHeaders = requestHeaders
+	row.ResponseHeaders = responseHeaders
+	return &row, nil
+}
+
+// FetchByPaymentID mengembalikan riwayat cek satu payment beserta total row yang cocok dengan rentang waktu.
+// Mode cursor mengambil PerPage+1 row supaya pemanggil tahu masih ada halaman berikutnya.
+func (p *paymentRecordCheckLogRepo) FetchByPaymentID(ctx context.Context, filter entity.CheckHistoryFilter) ([]entity.PaymentRecordCheckLog, int, error) {
+	where := []string{"payment_id = $1", "deleted_at IS NULL"}
+	args := []any{filter.PaymentID}
+	if !filter.From.IsZero() {
+		args = append(args, filter.From)
+		where = append(where, fmt.Sprintf("occurred_at >= $%d", len(args)))
+	}
+	if !filter.To.IsZero() {
+		args = append(args, filter.To)
+		where = append(where, fmt.Sprintf("occurred_at < $%d", len(args)))
+	}
+
+	var total int
+	countQuery := "SELECT COUNT(*) FROM payment_record_check_logs WHERE " + strings.Join(where, " AND ")
+	if err := p.DB.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
+		p.logger.Error().Err(err).Msg("❌ Failed to count payment record check logs")
+		return nil, 0, err
+	}
+
+	order, cmp := "DESC", "<"
+	if filter.Ascending {
+		order, cmp = "ASC", ">"
 	}
-	defer func() {
-		// Pastikan rollback bila belum commit
-		_ = tx.Rollback()
-	}()
 
-	if err := p.Store(ctx, tx, &logRow); err != nil {
-		p.logger.Error().Err(err).Msg("❌ Failed to store payment record log")
-		return err
+	limit, offset := filter.PerPage, (filter.Page-1)*filter.PerPage
+	if filter.Cursor != nil {
+		args = append(args, filter.Cursor.OccurredAt, filter.Cursor.ID)
+		where = append(where, fmt.Sprintf("(occurred_at, id) %s ($%d, $%d)", cmp, len(args)-1, len(args)))
+		limit, offset = filter.PerPage+1, 0
 	}
+	args = append(args, limit, offset)
 
-	if err := tx.Commit(); err != nil {
-		p.logger.Error().Err(err).Msg("❌ Failed to commit transaction")
-		return err
+	query := "SELECT id, payment_id, occurred_at, method, url, request_headers, request_body, " +
+		"response_headers, response_body, COALESCE(status_code, 0), delay_seconds, created_at, updated_at " +
+		"FROM payment_record_check_logs WHERE " + strings.Join(where, " AND ") +
+		fmt.Sprintf(" ORDER BY occurred_at %s, id %s LIMIT $%d OFFSET $%d", order, order, len(args)-1, len(args))
+
+	rows, err := p.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		p.logger.Error().Err(err).Msg("❌ Failed to fetch payment record check logs")
+		return nil, 0, err
 	}
-	return nil
+	defer rows.Close()
+
+	var logs []entity.PaymentRecordCheckLog
+	for rows.Next() {
+		var (
+			row             entity.PaymentRecordCheckLog
+			requestHeaders  []byte
+			responseHeaders []byte
+		)
+		if err := rows.Scan(
+			&row.ID, &row.PaymentID, &row.OccurredAt, &row.Method, &row.URL, &requestHeaders, &row.RequestBody,
+			&responseHeaders, &row.ResponseBody, &row.StatusCode, &row.DelaySeconds, &row.CreatedAt, &row.UpdatedAt,
+		); err != nil {
+			p.logger.Error().Err(err).Msg("❌ Failed to scan payment record check log")
+			return nil, 0, err
+		}
+		row.RequestHeaders = requestHeaders
+		row.ResponseHeaders = responseHeaders
+		logs = append(logs, row)
+	}
+	return logs, total, rows.Err()
+}
+
+func marshalHeaders(h http.Header) ([]byte, error) {
+	return json.Marshal(h)
 }
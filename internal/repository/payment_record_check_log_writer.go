@@ -0,0 +1,245 @@
+package repository
+
+import (
+	"beta-payment-api-client/internal/entity"
+	"beta-payment-api-client/internal/pkg/metrics"
+	"context"
+	"database/sql"
+	"errors"
+	"expvar"
+	"fmt"
+	"github.com/rs/zerolog"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	CheckLogOverflowDrop  = "drop"  // buffer penuh → row dibuang, polling tidak pernah tertahan
+	CheckLogOverflowBlock = "block" // buffer penuh → worker polling menunggu sampai ada ruang
+
+	checkLogColumns      = 13
+	checkLogMaxBatchSize = 65535 / checkLogColumns // batas parameter PostgreSQL per statement
+	checkLogFlushTimeout = 10 * time.Second
+	checkLogFlushRetries = 3 // percobaan ulang per batch sebelum row dihitung failed
+	checkLogRetryBackoff = 200 * time.Millisecond
+)
+
+var ErrCheckLogWriterClosed = errors.New("check log writer closed")
+
+// CheckLogWriterConfig mengatur buffer & batch penulisan check log.
+type CheckLogWriterConfig struct {
+	BufferSize    int
+	BatchSize     int
+	FlushInterval time.Duration
+	Overflow      string
+}
+
+// CheckLogWriter menulis check log secara asynchronous: row diterima lewat channel ber-buffer
+// lalu di-INSERT multi-row tiap BatchSize row atau tiap FlushInterval, mana yang lebih dulu.
+type CheckLogWriter struct {
+	DB     *sql.DB
+	config CheckLogWriterConfig
+	rows   chan entity.PaymentRecordCheckLog
+	logger zerolog.Logger
+
+	mu      sync.Mutex
+	closed  bool
+	pending sync.WaitGroup // Enqueue yang sedang berjalan; buffer baru dikuras setelah semuanya selesai
+	stop    chan struct{}  // ditutup Close: membangunkan Enqueue yang menunggu (policy block)
+	drain   chan struct{}  // ditutup setelah pending selesai: run menguras buffer lalu berhenti
+	done    chan struct{}
+}
+
+func NewCheckLogWriter(db *sql.DB, config CheckLogWriterConfig, logger zerolog.Logger) *CheckLogWriter {
+	if config.BufferSize <= 0 {
+		config.BufferSize = 1
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = 1
+	}
+	if config.BatchSize > checkLogMaxBatchSize {
+		config.BatchSize = checkLogMaxBatchSize
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = time.Second
+	}
+	if config.Overflow != CheckLogOverflowBlock {
+		config.Overflow = CheckLogOverflowDrop
+	}
+
+	w := &CheckLogWriter{
+		DB:     db,
+		config: config,
+		rows:   make(chan entity.PaymentRecordCheckLog, config.BufferSize),
+		logger: logger,
+		stop:   make(chan struct{}),
+		drain:  make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	metrics.CheckLogWriter.Set("buffered", expvar.Func(func() any { return len(w.rows) }))
+	metrics.CheckLogWriter.Set("capacity", expvar.Func(func() any { return cap(w.rows) }))
+	go w.run()
+	return w
+}
+
+// Enqueue memasukkan row ke buffer. Policy drop tidak pernah menunggu; policy block menunggu
+// sampai ada ruang, ctx selesai, atau writer ditutup.
+func (w *CheckLogWriter) Enqueue(ctx context.Context, row entity.PaymentRecordCheckLog) error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		metrics.CheckLogWriter.Add("dropped", 1)
+		return ErrCheckLogWriterClosed
+	}
+	w.pending.Add(1)
+	w.mu.Unlock()
+	defer w.pending.Done()
+
+	if w.config.Overflow == CheckLogOverflowDrop {
+		select {
+		case w.rows <- row:
+			metrics.CheckLogWriter.Add("enqueued", 1)
+			return nil
+		default:
+			metrics.CheckLogWriter.Add("dropped", 1)
+			return fmt.Errorf("check log buffer full (%d), row dropped", w.config.BufferSize)
+		}
+	}
+
+	select {
+	case w.rows <- row:
+		metrics.CheckLogWriter.Add("enqueued", 1)
+		return nil
+	default:
+	}
+	metrics.CheckLogWriter.Add("blocked", 1)
+	select {
+	case w.rows <- row:
+		metrics.CheckLogWriter.Add("enqueued", 1)
+		return nil
+	case <-ctx.Done():
+		metrics.CheckLogWriter.Add("dropped", 1)
+		return ctx.Err()
+	case <-w.stop:
+		metrics.CheckLogWriter.Add("dropped", 1)
+		return ErrCheckLogWriterClosed
+	}
+}
+
+// Close berhenti menerima row, menulis semua row yang masih di buffer, lalu menunggu flush terakhir.
+func (w *CheckLogWriter) Close(ctx context.Context) error {
+	w.mu.Lock()
+	if !w.closed {
+		w.closed = true
+		close(w.stop)
+		go func() {
+			w.pending.Wait()
+			close(w.drain)
+		}()
+	}
+	w.mu.Unlock()
+
+	select {
+	case <-w.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (w *CheckLogWriter) run() {
+	defer close(w.done)
+	ticker := time.NewTicker(w.config.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]entity.PaymentRecordCheckLog, 0, w.config.BatchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		w.flush(batch)
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case row := <-w.rows:
+			batch = append(batch, row)
+			if len(batch) >= w.config.BatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-w.drain:
+			// Tidak ada lagi Enqueue yang berjalan → buffer tinggal dikuras
+			for {
+				select {
+				case row := <-w.rows:
+					batch = append(batch, row)
+					if len(batch) >= w.config.BatchSize {
+						flush()
+					}
+				default:
+					flush()
+					w.logger.Info().Msg("🔒 Check log writer flushed and closed")
+					return
+				}
+			}
+		}
+	}
+}
+
+// flush menulis satu batch; INSERT gagal dicoba ulang dengan backoff eksponensial sebelum row dihitung failed.
+// Selama retry, run tidak membaca buffer: row baru tertampung di buffer lalu tunduk pada overflow policy
+// (drop → dibuang & dihitung dropped, block → worker polling menunggu), jadi error DB sesaat tidak langsung membuang batch.
+func (w *CheckLogWriter) flush(batch []entity.PaymentRecordCheckLog) {
+	started := time.Now()
+	backoff := checkLogRetryBackoff
+	for attempt := 0; ; attempt++ {
+		err := w.insert(batch)
+		if err == nil {
+			break
+		}
+		if attempt >= checkLogFlushRetries {
+			metrics.CheckLogWriter.Add("failed", int64(len(batch)))
+			w.logger.Error().Err(err).Int("rows", len(batch)).Int("attempts", attempt+1).Msg("❌ Failed to write check log batch")
+			return
+		}
+		metrics.CheckLogWriter.Add("retried", 1)
+		w.logger.Warn().Err(err).Int("rows", len(batch)).Msgf("‼️ Failed to write check log batch, retrying in %s", backoff)
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+
+	metrics.CheckLogWriter.Add("written", int64(len(batch)))
+	metrics.CheckLogWriter.Add("batches", 1)
+	w.logger.Debug().Int("rows", len(batch)).Dur("took", time.Since(started)).Msg("📝 Check log batch written")
+}
+
+// insert menulis satu batch dengan satu INSERT multi-row. created_at & updated_at diisi dari row
+// (waktu aplikasi yang sama dengan occurred_at), bukan CURRENT_TIMESTAMP database.
+func (w *CheckLogWriter) insert(batch []entity.PaymentRecordCheckLog) error {
+	ctx, cancel := context.WithTimeout(context.Background(), checkLogFlushTimeout)
+	defer cancel()
+
+	placeholders := make([]string, 0, len(batch))
+	args := make([]any, 0, len(batch)*checkLogColumns)
+	for _, row := range batch {
+		params := make([]string, checkLogColumns)
+		for i := range params {
+			params[i] = fmt.Sprintf("$%d", len(args)+i+1)
+		}
+		placeholders = append(placeholders, "("+strings.Join(params, ", ")+")")
+		args = append(args,
+			row.ID, row.PaymentID, row.OccurredAt, row.Method, row.URL, []byte(row.RequestHeaders), row.RequestBody,
+			[]byte(row.ResponseHeaders), row.ResponseBody, row.StatusCode, row.DelaySeconds, row.CreatedAt, row.UpdatedAt)
+	}
+
+	_, err := w.DB.ExecContext(ctx,
+		"INSERT INTO payment_record_check_logs ("+
+			"id, payment_id, occurred_at, method, url, request_headers, request_body, response_headers, response_body, "+
+			"status_code, delay_seconds, created_at, updated_at) VALUES "+strings.Join(placeholders, ", "),
+		args...)
+	return err
+}
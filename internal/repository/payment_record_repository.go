@@ -1,22 +1,23 @@
 package repository
 
 import (
-	"beta-payment-api-client/internal/dto"
 	"beta-payment-api-client/internal/entity"
 	pkgKafka "beta-payment-api-client/internal/pkg/kafka"
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
 	"github.com/segmentio/kafka-go"
-	"io"
-	"log"
-	"net/http"
+	"strconv"
 	"time"
 )
 
+var ErrPollingTaskNotFound = errors.New("polling task not found")
+
 type PaymentStatus struct {
 	Status string `json:"status"`
 }
@@ -24,25 +25,69 @@ type PaymentStatus struct {
 type PaymentRecordRepository interface {
 	SetNextRetry(ctx context.Context, id uuid.UUID, delay time.Duration) error
 	GetNextRetry(ctx context.Context, id uuid.UUID) (time.Time, error)
+	GetNextRetries(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]time.Time, error)
 	PublishSuccessEvent(ctx context.Context, id uuid.UUID) error
-	FetchPaymentStatus(ctx context.Context, id uuid.UUID) (string, *entity.PaymentRecordCheckHTTP, error)
+	PublishTimeoutEvent(ctx context.Context, id uuid.UUID) error
 	ReadKafkaMessage(ctx context.Context) (string, error)
 	Store(ctx context.Context, tx *sql.Tx, payment *entity.PaymentRecord) error
+	UpdateStatus(ctx context.Context, tx *sql.Tx, id uuid.UUID, status entity.PaymentStatus) error
+	FetchStatusForUpdate(ctx context.Context, tx *sql.Tx, id uuid.UUID) (entity.PaymentStatus, error)
 	FetchByID(ctx context.Context, id uuid.UUID) (*entity.PaymentRecord, error)
 	FetchByIDRedis(ctx context.Context, id uuid.UUID) (int64, error)
 	StoreRedis(ctx context.Context, id uuid.UUID) error
-	PersistPollingTask(ctx context.Context, id uuid.UUID) error
+	PersistPollingTask(ctx context.Context, task entity.PollingTask) error
+	UpdatePollingTask(ctx context.Context, task entity.PollingTask) (bool, error)
 	RemovePollingTask(ctx context.Context, id uuid.UUID) error
-	RestorePollingTasks(ctx context.Context) ([]uuid.UUID, error)
+	RestorePollingTasks(ctx context.Context) ([]entity.PollingTask, error)
+	ListPollingTasks(ctx context.Context, filter entity.PollingTaskFilter, now time.Time) ([]entity.PollingTask, int, error)
+	IndexPollingTasks(ctx context.Context, tasks []entity.PollingTask) error
+	FetchPollingTask(ctx context.Context, id uuid.UUID) (*entity.PollingTask, error)
+	SetPollingPaused(ctx context.Context, paused bool) error
+	SetPollingTaskPaused(ctx context.Context, id uuid.UUID, paused bool) error
+	SetPollingProviderPaused(ctx context.Context, provider string, until time.Time) error
+	ResumePollingProvider(ctx context.Context, provider string) error
+	FetchPauseStates(ctx context.Context) (entity.PollingPauseState, error)
+	PublishPollingControl(ctx context.Context, msg entity.PollingControl) error
+	SubscribePollingControl(ctx context.Context) (<-chan entity.PollingControl, error)
+	AcquirePollingLease(ctx context.Context, id uuid.UUID, owner string, ttl time.Duration) (bool, error)
+	RenewPollingLeases(ctx context.Context, ids []uuid.UUID, owner string, ttl time.Duration) ([]uuid.UUID, error)
+	ReleasePollingLease(ctx context.Context, id uuid.UUID, owner string) error
+	GetPollingLeaseOwner(ctx context.Context, id uuid.UUID) (string, error)
+	FetchPollingLeaseOwners(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]string, error)
 }
 
+// Lease hanya boleh diperpanjang / dilepas oleh pemiliknya (compare-and-set)
+var (
+	renewLeaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+  return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+	// State task hanya ditulis ulang selama task masih terdaftar, supaya task yang sudah
+	// dihapus (dibatalkan / selesai di replica lain) tidak hidup lagi.
+	// KEYS: polling_tasks, polling_task_states, polling_task_started; ARGV: id, state, score
+	updateTaskScript = redis.NewScript(`
+if redis.call("SISMEMBER", KEYS[1], ARGV[1]) == 0 then
+  return 0
+end
+redis.call("HSET", KEYS[2], ARGV[1], ARGV[2])
+redis.call("ZADD", KEYS[3], ARGV[3], ARGV[1])
+return 1`)
+	releaseLeaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+  return redis.call("DEL", KEYS[1])
+end
+return 0`)
+)
+
 type paymentRecordRepoRedis struct {
 	redisClient              *redis.Client
 	kafkaProducerClient      *pkgKafka.KafkaProducerClient
 	kafkaConsumerClient      *pkgKafka.KafkaConsumerClient
 	DB                       *sql.DB
-	paymentServerAPIKey      string
 	KafkaTopicPaymentSuccess string
+	KafkaTopicPaymentTimeout string
+	logger                   zerolog.Logger
 }
 
 func NewPaymentRecordRepository(
@@ -50,15 +95,17 @@ func NewPaymentRecordRepository(
 	kafkaProducerClient *pkgKafka.KafkaProducerClient,
 	kafkaConsumerClient *pkgKafka.KafkaConsumerClient,
 	db *sql.DB,
-	paymentServerAPIKey string,
-	KafkaTopicPaymentSuccess string) PaymentRecordRepository {
+	KafkaTopicPaymentSuccess string,
+	KafkaTopicPaymentTimeout string,
+	logger zerolog.Logger) PaymentRecordRepository {
 	return &paymentRecordRepoRedis{
 		redisClient:              redisClient,
 		kafkaProducerClient:      kafkaProducerClient,
 		kafkaConsumerClient:      kafkaConsumerClient,
 		DB:                       db,
-		paymentServerAPIKey:      paymentServerAPIKey,
 		KafkaTopicPaymentSuccess: KafkaTopicPaymentSuccess,
+		KafkaTopicPaymentTimeout: KafkaTopicPaymentTimeout,
+		logger:                   logger,
 	}
 }
 
@@ -77,6 +124,33 @@ func (p *paymentRecordRepoRedis) GetNextRetry(ctx context.Context, id uuid.UUID)
 	return time.Unix(timestamp, 0), nil
 }
 
+// GetNextRetries mengambil jadwal cek berikutnya banyak task sekaligus (MGET per chunk);
+// task tanpa jadwal tidak ada di map.
+func (p *paymentRecordRepoRedis) GetNextRetries(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]time.Time, error) {
+	retries := make(map[uuid.UUID]time.Time, len(ids))
+	for start := 0; start < len(ids); start += pollingLeaseBatchSize {
+		chunk := ids[start:min(start+pollingLeaseBatchSize, len(ids))]
+		keys := make([]string, len(chunk))
+		for i, id := range chunk {
+			keys[i] = fmt.Sprintf("retry:%s", id.String())
+		}
+		values, err := p.redisClient.MGet(ctx, keys...).Result()
+		if err != nil {
+			return nil, err
+		}
+		for i, value := range values {
+			raw, ok := value.(string)
+			if !ok {
+				continue
+			}
+			if timestamp, err := strconv.ParseInt(raw, 10, 64); err == nil {
+				retries[chunk[i]] = time.Unix(timestamp, 0)
+			}
+		}
+	}
+	return retries, nil
+}
+
 func (p *paymentRecordRepoRedis) PublishSuccessEvent(ctx context.Context, id uuid.UUID) error {
 	msg := kafka.Message{
 		Key:   []byte(fmt.Sprintf("%s", p.KafkaTopicPaymentSuccess)),
@@ -84,10 +158,24 @@ func (p *paymentRecordRepoRedis) PublishSuccessEvent(ctx context.Context, id uui
 	}
 	err := p.kafkaProducerClient.Writer.WriteMessages(ctx, msg)
 	if err != nil {
-		log.Println("Error publishing Kafka message:", err)
+		p.logger.Error().Err(err).Msgf("❌ Error publishing Kafka message: %s", id)
+		return err
+	}
+	p.logger.Info().Msgf("✅ Kafka message published: %s", id)
+	return nil
+}
+
+func (p *paymentRecordRepoRedis) PublishTimeoutEvent(ctx context.Context, id uuid.UUID) error {
+	msg := kafka.Message{
+		Key:   []byte(p.KafkaTopicPaymentTimeout),
+		Value: []byte(id.String()),
+	}
+	err := p.kafkaProducerClient.TimeoutWriter.WriteMessages(ctx, msg)
+	if err != nil {
+		p.logger.Error().Err(err).Msgf("❌ Error publishing Kafka timeout message: %s", id)
 		return err
 	}
-	log.Println("✅ Kafka message published:", msg.Value)
+	p.logger.Info().Msgf("✅ Kafka timeout message published: %s", id)
 	return nil
 }
 
@@ -99,115 +187,44 @@ func (p *paymentRecordRepoRedis) ReadKafkaMessage(ctx context.Context) (string,
 	return string(msg.Value), nil
 }
 
-func (p *paymentRecordRepoRedis) FetchPaymentStatusYangLama(ctx context.Context, id uuid.UUID) (string, *entity.PaymentRecordCheckHTTP, error) {
-	url := fmt.Sprintf("http://localhost:8080/api/v1/payments/%s", id.String())
-
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		log.Println("❌ Failed to create request:", err)
-		return "", nil, err
-	}
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", p.paymentServerAPIKey))
+func (p *paymentRecordRepoRedis) Store(ctx context.Context, tx *sql.Tx, paymentRecord *entity.PaymentRecord) error {
+	return tx.QueryRowContext(
+		ctx,
+		"INSERT INTO payment_records (id, tag, provider, description, amount, status) VALUES ($1, $2, $3, $4, $5, $6) RETURNING created_at, updated_at",
+		paymentRecord.ID, paymentRecord.Tag, paymentRecord.Provider, paymentRecord.Description, paymentRecord.Amount, paymentRecord.Status,
+	).Scan(&paymentRecord.CreatedAt, &paymentRecord.UpdatedAt)
+}
 
-	resp, err := http.DefaultClient.Do(req)
+func (p *paymentRecordRepoRedis) UpdateStatus(ctx context.Context, tx *sql.Tx, id uuid.UUID, status entity.PaymentStatus) error {
+	result, err := tx.ExecContext(
+		ctx,
+		"UPDATE payment_records SET status = $2, updated_at = CURRENT_TIMESTAMP WHERE id = $1 AND deleted_at is null",
+		id, status,
+	)
 	if err != nil {
-		log.Println("❌ HTTP request failed:", err)
-		return "", &entity.PaymentRecordCheckHTTP{Context: ctx, ID: id, Request: req, ResponseBody: nil, StatusCode: 0}, err
+		return err
 	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
+	affected, err := result.RowsAffected()
 	if err != nil {
-		log.Println("❌ Failed to read body:", err)
-		return "", &entity.PaymentRecordCheckHTTP{Context: ctx, ID: id, Request: req, ResponseBody: nil, StatusCode: resp.StatusCode}, err
-	}
-
-	log.Println("📦 Payment API response:", string(body))
-
-	var result dto.GetPaymentByIDResponse
-	if err := json.Unmarshal(body, &result); err != nil {
-		log.Println("❌ Failed to unmarshal JSON:", err)
-		return "", nil, err
+		return err
 	}
-
-	return result.Data.Status, &entity.PaymentRecordCheckHTTP{Context: ctx, ID: id, Request: req, ResponseBody: body, StatusCode: resp.StatusCode}, nil
-}
-
-func (p *paymentRecordRepoRedis) FetchPaymentStatus(ctx context.Context, id uuid.UUID) (string, *entity.PaymentRecordCheckHTTP, error) {
-	url := fmt.Sprintf("http://localhost:8080/api/v1/payments/%s", id.String())
-
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		log.Println("❌ Failed to create request:", err)
-		return "", nil, err
+	if affected == 0 {
+		return sql.ErrNoRows
 	}
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", p.paymentServerAPIKey))
-
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		log.Println("❌ HTTP request failed:", err)
-		return "", &entity.PaymentRecordCheckHTTP{
-			Context:      ctx,
-			ID:           id,
-			Request:      req,
-			Response:     nil, // gagal sebelum resp
-			ResponseBody: nil,
-			StatusCode:   0,
-		}, err
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		log.Println("❌ Failed to read body:", err)
-		return "", &entity.PaymentRecordCheckHTTP{
-			Context:      ctx,
-			ID:           id,
-			Request:      req,
-			Response:     resp, // ada header/status
-			ResponseBody: nil,
-			StatusCode:   resp.StatusCode,
-		}, err
-	}
-
-	log.Println("📦 Payment API response:", string(body))
-
-	var result dto.GetPaymentByIDResponse
-	if err := json.Unmarshal(body, &result); err != nil {
-		log.Println("❌ Failed to unmarshal JSON:", err)
-		// kirim juga objek untuk logging
-		return "", &entity.PaymentRecordCheckHTTP{
-			Context:      ctx,
-			ID:           id,
-			Request:      req,
-			Response:     resp,
-			ResponseBody: body,
-			StatusCode:   resp.StatusCode,
-		}, err
-	}
-
-	return result.Data.Status, &entity.PaymentRecordCheckHTTP{
-		Context:      ctx,
-		ID:           id,
-		Request:      req,
-		Response:     resp,
-		ResponseBody: body,
-		StatusCode:   resp.StatusCode,
-	}, nil
+	return nil
 }
 
-func (p *paymentRecordRepoRedis) Store(ctx context.Context, tx *sql.Tx, paymentRecord *entity.PaymentRecord) error {
-	return tx.QueryRowContext(
-		ctx,
-		"INSERT INTO payment_records (id, tag, description, amount, status) VALUES ($1, $2, $3, $4, $5) RETURNING created_at, updated_at",
-		paymentRecord.ID, paymentRecord.Tag, paymentRecord.Description, paymentRecord.Amount, paymentRecord.Status,
-	).Scan(&paymentRecord.CreatedAt, &paymentRecord.UpdatedAt)
+func (p *paymentRecordRepoRedis) FetchStatusForUpdate(ctx context.Context, tx *sql.Tx, id uuid.UUID) (entity.PaymentStatus, error) {
+	var status entity.PaymentStatus
+	err := tx.QueryRowContext(ctx, "SELECT status FROM payment_records WHERE id = $1 AND deleted_at is null FOR UPDATE", id).
+		Scan(&status)
+	return status, err
 }
 
 func (p *paymentRecordRepoRedis) FetchByID(ctx context.Context, id uuid.UUID) (*entity.PaymentRecord, error) {
 	var paymentRecord entity.PaymentRecord
-	err := p.DB.QueryRowContext(ctx, "SELECT id, tag, amount, status, created_at, updated_at FROM payment_records WHERE id = $1 AND deleted_at is null", id).
-		Scan(&paymentRecord.ID, &paymentRecord.Tag, &paymentRecord.Amount, &paymentRecord.Status, &paymentRecord.CreatedAt, &paymentRecord.UpdatedAt)
+	err := p.DB.QueryRowContext(ctx, "SELECT id, tag, provider, description, amount, status, created_at, updated_at FROM payment_records WHERE id = $1 AND deleted_at is null", id).
+		Scan(&paymentRecord.ID, &paymentRecord.Tag, &paymentRecord.Provider, &paymentRecord.Description, &paymentRecord.Amount, &paymentRecord.Status, &paymentRecord.CreatedAt, &paymentRecord.UpdatedAt)
 
 	if err != nil {
 		return nil, err
@@ -225,28 +242,395 @@ func (p *paymentRecordRepoRedis) StoreRedis(ctx context.Context, id uuid.UUID) e
 	return p.redisClient.Set(ctx, redisKey, "1", 10*time.Minute).Err()
 }
 
-func (p *paymentRecordRepoRedis) PersistPollingTask(ctx context.Context, id uuid.UUID) error {
-	return p.redisClient.SAdd(ctx, "polling_tasks", id.String()).Err()
+func (p *paymentRecordRepoRedis) PersistPollingTask(ctx context.Context, task entity.PollingTask) error {
+	state, err := json.Marshal(task)
+	if err != nil {
+		return err
+	}
+	pipe := p.redisClient.TxPipeline()
+	pipe.SAdd(ctx, "polling_tasks", task.ID.String())
+	pipe.HSet(ctx, "polling_task_states", task.ID.String(), state)
+	pipe.ZAdd(ctx, "polling_task_started", pollingTaskIndexEntry(task))
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// UpdatePollingTask menyimpan state task yang sudah terdaftar; false jika task sudah dihapus dari Redis.
+func (p *paymentRecordRepoRedis) UpdatePollingTask(ctx context.Context, task entity.PollingTask) (bool, error) {
+	state, err := json.Marshal(task)
+	if err != nil {
+		return false, err
+	}
+	entry := pollingTaskIndexEntry(task)
+	keys := []string{"polling_tasks", "polling_task_states", "polling_task_started"}
+	updated, err := updateTaskScript.Run(ctx, p.redisClient, keys, task.ID.String(), state, entry.Score).Int()
+	if err != nil {
+		return false, err
+	}
+	return updated == 1, nil
+}
+
+// pollingTaskIndexEntry entry index polling_task_started: score started_at (ms), member id;
+// score sama diurutkan Redis per member, jadi urutan list = started_at lalu id.
+func pollingTaskIndexEntry(task entity.PollingTask) redis.Z {
+	return redis.Z{Score: float64(task.StartedAt.UnixMilli()), Member: task.ID.String()}
+}
+
+// IndexPollingTasks memasukkan task yang belum ada di index (task dari versi sebelum index ada).
+func (p *paymentRecordRepoRedis) IndexPollingTasks(ctx context.Context, tasks []entity.PollingTask) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+	entries := make([]redis.Z, len(tasks))
+	for i, task := range tasks {
+		entries[i] = pollingTaskIndexEntry(task)
+	}
+	return p.redisClient.ZAddNX(ctx, "polling_task_started", entries...).Err()
 }
 
 func (p *paymentRecordRepoRedis) RemovePollingTask(ctx context.Context, id uuid.UUID) error {
-	return p.redisClient.SRem(ctx, "polling_tasks", id.String()).Err()
+	pipe := p.redisClient.TxPipeline()
+	pipe.SRem(ctx, "polling_tasks", id.String())
+	pipe.HDel(ctx, "polling_task_states", id.String())
+	pipe.ZRem(ctx, "polling_task_started", id.String())
+	pipe.SRem(ctx, "polling_paused_tasks", id.String())
+	_, err := pipe.Exec(ctx)
+	return err
 }
 
-func (p *paymentRecordRepoRedis) RestorePollingTasks(ctx context.Context) ([]uuid.UUID, error) {
-	ids, err := p.redisClient.SMembers(ctx, "polling_tasks").Result()
-	if err != nil {
+// RestorePollingTasks membaca semua task (marker + state) dalam satu round-trip: SMEMBERS + HGETALL.
+func (p *paymentRecordRepoRedis) RestorePollingTasks(ctx context.Context) ([]entity.PollingTask, error) {
+	pipe := p.redisClient.Pipeline()
+	idsCmd := pipe.SMembers(ctx, "polling_tasks")
+	statesCmd := pipe.HGetAll(ctx, "polling_task_states")
+	if _, err := pipe.Exec(ctx); err != nil {
 		return nil, err
 	}
+	states := statesCmd.Val()
 
-	var result []uuid.UUID
-	for _, idStr := range ids {
+	var result []entity.PollingTask
+	for _, idStr := range idsCmd.Val() {
 		id, err := uuid.Parse(idStr)
 		if err != nil {
-			log.Println("❌ Invalid UUID in Redis:", idStr)
+			p.logger.Warn().Msgf("❌ Invalid UUID in Redis: %s", idStr)
 			continue
 		}
-		result = append(result, id)
+
+		task := entity.PollingTask{ID: id}
+		// Task lama (sebelum ada state) tidak punya policy → biarkan kosong, usecase pakai default
+		if state, ok := states[idStr]; ok {
+			if err := json.Unmarshal([]byte(state), &task); err != nil {
+				p.logger.Warn().Err(err).Msgf("❌ Invalid polling task state in Redis: %s", idStr)
+				task = entity.PollingTask{ID: id}
+			}
+		}
+		result = append(result, task)
 	}
 	return result, nil
 }
+
+// pollingTaskScanChunk jumlah entry index yang dibaca per langkah saat filter status harus di-scan
+const pollingTaskScanChunk = 500
+
+// ListPollingTasks membaca satu halaman task dari index polling_task_started (urut started_at, lalu id).
+// Filter umur dipetakan ke rentang score; tanpa filter status hanya halaman yang diminta yang dibaca
+// (ZCOUNT + ZRANGE LIMIT + HMGET). Status hanya ada di state task, jadi filter status men-scan
+// rentang itu per chunk tanpa menampung seluruh task di memori.
+func (p *paymentRecordRepoRedis) ListPollingTasks(ctx context.Context, filter entity.PollingTaskFilter, now time.Time) ([]entity.PollingTask, int, error) {
+	minScore, maxScore := "-inf", "+inf"
+	if filter.MaxAge > 0 {
+		minScore = strconv.FormatInt(now.Add(-filter.MaxAge).UnixMilli(), 10)
+	}
+	if filter.MinAge > 0 {
+		maxScore = strconv.FormatInt(now.Add(-filter.MinAge).UnixMilli(), 10)
+	}
+	offset := int64((filter.Page - 1) * filter.PerPage)
+	limit := int64(filter.PerPage)
+
+	if filter.Status == "" {
+		total, err := p.redisClient.ZCount(ctx, "polling_task_started", minScore, maxScore).Result()
+		if err != nil {
+			return nil, 0, err
+		}
+		ids, err := p.rangePollingTaskIndex(ctx, minScore, maxScore, offset, limit)
+		if err != nil {
+			return nil, 0, err
+		}
+		tasks, err := p.fetchPollingTaskStates(ctx, ids)
+		if err != nil {
+			return nil, 0, err
+		}
+		return tasks, int(total), nil
+	}
+
+	var page []entity.PollingTask
+	total := 0
+	for start := int64(0); ; start += pollingTaskScanChunk {
+		ids, err := p.rangePollingTaskIndex(ctx, minScore, maxScore, start, pollingTaskScanChunk)
+		if err != nil {
+			return nil, 0, err
+		}
+		tasks, err := p.fetchPollingTaskStates(ctx, ids)
+		if err != nil {
+			return nil, 0, err
+		}
+		for _, task := range tasks {
+			if !filter.Match(task, now) {
+				continue
+			}
+			if int64(total) >= offset && int64(len(page)) < limit {
+				page = append(page, task)
+			}
+			total++
+		}
+		if len(ids) < pollingTaskScanChunk {
+			return page, total, nil
+		}
+	}
+}
+
+func (p *paymentRecordRepoRedis) rangePollingTaskIndex(ctx context.Context, minScore, maxScore string, offset, count int64) ([]string, error) {
+	return p.redisClient.ZRangeArgs(ctx, redis.ZRangeArgs{
+		Key:     "polling_task_started",
+		Start:   minScore,
+		Stop:    maxScore,
+		ByScore: true,
+		Offset:  offset,
+		Count:   count,
+	}).Result()
+}
+
+// fetchPollingTaskStates membaca state banyak task dengan satu HMGET; task tanpa state
+// (task lama yang hanya punya marker) dikembalikan dengan ID saja, seperti RestorePollingTasks.
+func (p *paymentRecordRepoRedis) fetchPollingTaskStates(ctx context.Context, idStrs []string) ([]entity.PollingTask, error) {
+	if len(idStrs) == 0 {
+		return nil, nil
+	}
+	states, err := p.redisClient.HMGet(ctx, "polling_task_states", idStrs...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := make([]entity.PollingTask, 0, len(idStrs))
+	for i, idStr := range idStrs {
+		id, err := uuid.Parse(idStr)
+		if err != nil {
+			p.logger.Warn().Msgf("❌ Invalid UUID in Redis: %s", idStr)
+			continue
+		}
+		task := entity.PollingTask{ID: id}
+		if state, ok := states[i].(string); ok {
+			if err := json.Unmarshal([]byte(state), &task); err != nil {
+				p.logger.Warn().Err(err).Msgf("❌ Invalid polling task state in Redis: %s", idStr)
+				task = entity.PollingTask{ID: id}
+			}
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, nil
+}
+
+func (p *paymentRecordRepoRedis) FetchPollingTask(ctx context.Context, id uuid.UUID) (*entity.PollingTask, error) {
+	task := entity.PollingTask{ID: id}
+	state, err := p.redisClient.HGet(ctx, "polling_task_states", id.String()).Bytes()
+	if err == nil {
+		if err := json.Unmarshal(state, &task); err != nil {
+			return nil, err
+		}
+		return &task, nil
+	}
+	if !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+
+	// Task lama tanpa state: cukup cek marker di set polling_tasks
+	exists, err := p.redisClient.SIsMember(ctx, "polling_tasks", id.String()).Result()
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrPollingTaskNotFound
+	}
+	return &task, nil
+}
+
+// SetPollingPaused mengaktifkan / menonaktifkan pause global untuk semua replica.
+// Resume global ikut menghapus pause per provider (operator sudah memperbaiki kredensial).
+func (p *paymentRecordRepoRedis) SetPollingPaused(ctx context.Context, paused bool) error {
+	if paused {
+		return p.redisClient.Set(ctx, "polling_paused", "1", 0).Err()
+	}
+	return p.redisClient.Del(ctx, "polling_paused", "polling_paused_providers").Err()
+}
+
+// SetPollingProviderPaused mem-pause semua task satu provider sampai until (epoch ms di hash).
+func (p *paymentRecordRepoRedis) SetPollingProviderPaused(ctx context.Context, provider string, until time.Time) error {
+	return p.redisClient.HSet(ctx, "polling_paused_providers", provider, until.UnixMilli()).Err()
+}
+
+func (p *paymentRecordRepoRedis) ResumePollingProvider(ctx context.Context, provider string) error {
+	return p.redisClient.HDel(ctx, "polling_paused_providers", provider).Err()
+}
+
+func (p *paymentRecordRepoRedis) SetPollingTaskPaused(ctx context.Context, id uuid.UUID, paused bool) error {
+	if paused {
+		return p.redisClient.SAdd(ctx, "polling_paused_tasks", id.String()).Err()
+	}
+	return p.redisClient.SRem(ctx, "polling_paused_tasks", id.String()).Err()
+}
+
+// FetchPauseStates mengembalikan pause global, per task, dan per provider dalam satu round-trip.
+func (p *paymentRecordRepoRedis) FetchPauseStates(ctx context.Context) (entity.PollingPauseState, error) {
+	pipe := p.redisClient.Pipeline()
+	globalCmd := pipe.Exists(ctx, "polling_paused")
+	tasksCmd := pipe.SMembers(ctx, "polling_paused_tasks")
+	providersCmd := pipe.HGetAll(ctx, "polling_paused_providers")
+	if _, err := pipe.Exec(ctx); err != nil {
+		return entity.PollingPauseState{}, err
+	}
+
+	state := entity.PollingPauseState{
+		All:       globalCmd.Val() > 0,
+		Tasks:     make(map[uuid.UUID]bool),
+		Providers: make(map[string]time.Time),
+	}
+	for _, member := range tasksCmd.Val() {
+		if id, err := uuid.Parse(member); err == nil {
+			state.Tasks[id] = true
+		}
+	}
+	for provider, until := range providersCmd.Val() {
+		if ms, err := strconv.ParseInt(until, 10, 64); err == nil {
+			state.Providers[provider] = time.UnixMilli(ms)
+		}
+	}
+	return state, nil
+}
+
+// pollingControlChannel channel pub/sub untuk pesan kontrol antar replica
+const pollingControlChannel = "polling_control"
+
+func (p *paymentRecordRepoRedis) PublishPollingControl(ctx context.Context, msg entity.PollingControl) error {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return p.redisClient.Publish(ctx, pollingControlChannel, payload).Err()
+}
+
+// SubscribePollingControl berlangganan pesan kontrol; channel ditutup saat ctx selesai.
+// Pesan yang terkirim saat koneksi terputus hilang, jadi pemanggil tetap perlu sinkron ulang berkala.
+func (p *paymentRecordRepoRedis) SubscribePollingControl(ctx context.Context) (<-chan entity.PollingControl, error) {
+	sub := p.redisClient.Subscribe(ctx, pollingControlChannel)
+	// Tunggu konfirmasi subscribe supaya pesan setelah fungsi ini kembali tidak terlewat
+	if _, err := sub.Receive(ctx); err != nil {
+		_ = sub.Close()
+		return nil, err
+	}
+
+	out := make(chan entity.PollingControl, 64)
+	go func() {
+		defer close(out)
+		defer sub.Close()
+		messages := sub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case m, ok := <-messages:
+				if !ok {
+					return
+				}
+				var msg entity.PollingControl
+				if err := json.Unmarshal([]byte(m.Payload), &msg); err != nil {
+					p.logger.Warn().Err(err).Msgf("‼️ Invalid polling control message: %q", m.Payload)
+					continue
+				}
+				select {
+				case out <- msg:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return out, nil
+}
+
+// pollingLeaseBatchSize jumlah key per MGET supaya satu command tidak terlalu besar
+const pollingLeaseBatchSize = 1000
+
+func pollingLeaseKey(id uuid.UUID) string {
+	return fmt.Sprintf("polling_lease:%s", id.String())
+}
+
+func (p *paymentRecordRepoRedis) AcquirePollingLease(ctx context.Context, id uuid.UUID, owner string, ttl time.Duration) (bool, error) {
+	key := pollingLeaseKey(id)
+	ok, err := p.redisClient.SetNX(ctx, key, owner, ttl).Result()
+	if err != nil || ok {
+		return ok, err
+	}
+	// Sudah ada lease: tetap dianggap sukses jika pemiliknya instance ini sendiri
+	renewed, err := renewLeaseScript.Run(ctx, p.redisClient, []string{key}, owner, ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, err
+	}
+	return renewed == 1, nil
+}
+
+// RenewPollingLeases memperpanjang lease milik owner dan mengembalikan id yang lease-nya sudah hilang.
+func (p *paymentRecordRepoRedis) RenewPollingLeases(ctx context.Context, ids []uuid.UUID, owner string, ttl time.Duration) ([]uuid.UUID, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	pipe := p.redisClient.Pipeline()
+	cmds := make([]*redis.Cmd, len(ids))
+	for i, id := range ids {
+		cmds[i] = renewLeaseScript.Eval(ctx, pipe, []string{pollingLeaseKey(id)}, owner, ttl.Milliseconds())
+	}
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+
+	var lost []uuid.UUID
+	for i, cmd := range cmds {
+		if renewed, err := cmd.Int(); err != nil || renewed == 0 {
+			lost = append(lost, ids[i])
+		}
+	}
+	return lost, nil
+}
+
+func (p *paymentRecordRepoRedis) ReleasePollingLease(ctx context.Context, id uuid.UUID, owner string) error {
+	return releaseLeaseScript.Run(ctx, p.redisClient, []string{pollingLeaseKey(id)}, owner).Err()
+}
+
+// FetchPollingLeaseOwners mengambil pemilik lease banyak task sekaligus (MGET per chunk);
+// task tanpa lease (expired / tidak pernah diambil) bernilai "".
+func (p *paymentRecordRepoRedis) FetchPollingLeaseOwners(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]string, error) {
+	owners := make(map[uuid.UUID]string, len(ids))
+	for start := 0; start < len(ids); start += pollingLeaseBatchSize {
+		chunk := ids[start:min(start+pollingLeaseBatchSize, len(ids))]
+		keys := make([]string, len(chunk))
+		for i, id := range chunk {
+			keys[i] = pollingLeaseKey(id)
+		}
+		values, err := p.redisClient.MGet(ctx, keys...).Result()
+		if err != nil {
+			return nil, err
+		}
+		for i, value := range values {
+			owner, _ := value.(string)
+			owners[chunk[i]] = owner
+		}
+	}
+	return owners, nil
+}
+
+func (p *paymentRecordRepoRedis) GetPollingLeaseOwner(ctx context.Context, id uuid.UUID) (string, error) {
+	owner, err := p.redisClient.Get(ctx, pollingLeaseKey(id)).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", nil
+	}
+	return owner, err
+}
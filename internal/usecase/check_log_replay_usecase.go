@@ -0,0 +1,85 @@
+package usecase
+
+import (
+	"beta-payment-api-client/internal/entity"
+	"beta-payment-api-client/internal/pkg/payment_provider"
+	"beta-payment-api-client/internal/pkg/redact"
+	"beta-payment-api-client/internal/repository"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"net/http"
+)
+
+type CheckLogReplayUseCase interface {
+	Replay(ctx context.Context, checkLogID uuid.UUID) (*entity.CheckLogReplay, error)
+}
+
+type checkLogReplayUseCase struct {
+	paymentRecordRepo         repository.PaymentRecordRepository
+	paymentRecordCheckLogRepo repository.PaymentRecordCheckLogRepository
+	paymentProviders          *payment_provider.Registry
+	redactor                  *redact.Redactor
+	logger                    zerolog.Logger
+}
+
+func NewCheckLogReplayUseCase(
+	paymentRecordRepo repository.PaymentRecordRepository,
+	paymentRecordCheckLogRepo repository.PaymentRecordCheckLogRepository,
+	paymentProviders *payment_provider.Registry,
+	redactor *redact.Redactor,
+	logger zerolog.Logger) CheckLogReplayUseCase {
+	return &checkLogReplayUseCase{
+		paymentRecordRepo:         paymentRecordRepo,
+		paymentRecordCheckLogRepo: paymentRecordCheckLogRepo,
+		paymentProviders:          paymentProviders,
+		redactor:                  redactor,
+		logger:                    logger,
+	}
+}
+
+// Replay mengirim ulang request dari satu row check log ke provider payment tersebut dan membandingkan
+// response-nya dengan yang tercatat. Read-only: tidak menulis check log, status payment, maupun state polling.
+// Body baru diredaksi dengan aturan yang sama dengan check log supaya field yang disamarkan tidak muncul sebagai diff.
+func (c *checkLogReplayUseCase) Replay(ctx context.Context, checkLogID uuid.UUID) (*entity.CheckLogReplay, error) {
+	c.logger.Info().Str("usecase", "Replay").Msgf("⚙️ Replaying check log %s", checkLogID)
+	checkLog, err := c.paymentRecordCheckLogRepo.FetchByID(ctx, checkLogID)
+	if err != nil {
+		return nil, err
+	}
+
+	paymentRecord, err := c.paymentRecordRepo.FetchByID(ctx, checkLog.PaymentID)
+	if err != nil {
+		return nil, fmt.Errorf("payment record %s: %w", checkLog.PaymentID, err)
+	}
+	providerName, err := c.paymentProviders.Resolve(paymentRecord.Provider, paymentRecord.Tag)
+	if err != nil {
+		return nil, err
+	}
+	provider, err := c.paymentProviders.Get(providerName)
+	if err != nil {
+		return nil, err
+	}
+
+	header := http.Header{}
+	if len(checkLog.RequestHeaders) > 0 {
+		if err := json.Unmarshal(checkLog.RequestHeaders, &header); err != nil {
+			return nil, fmt.Errorf("decode request headers of check log %s: %w", checkLogID, err)
+		}
+	}
+
+	checkHTTP, err := provider.Replay(ctx, checkLog.Method, checkLog.URL, header, checkLog.RequestBody)
+	if err != nil {
+		return nil, err
+	}
+
+	replay := entity.NewCheckLogReplay(*checkLog, providerName, checkHTTP.StatusCode, c.redactor.Body(checkHTTP.ResponseBody))
+	c.logger.Info().
+		Str("check_log_id", checkLogID.String()).
+		Bool("status_changed", replay.StatusChanged).
+		Bool("body_changed", replay.BodyChanged).
+		Msg("✅ Check log replayed")
+	return &replay, nil
+}
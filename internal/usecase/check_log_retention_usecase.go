@@ -0,0 +1,166 @@
+package usecase
+
+import (
+	"beta-payment-api-client/internal/entity"
+	"beta-payment-api-client/internal/repository"
+	"context"
+	"errors"
+	"github.com/rs/zerolog"
+	"sync"
+	"time"
+)
+
+// CheckLogRetentionConfig mengatur partisi & retention payment_record_check_logs.
+type CheckLogRetentionConfig struct {
+	RetentionDays          int  // <= 0 = check log tidak pernah dihapus
+	FinalizedRetentionDays int  // retention archive percobaan terakhir payment final; <= 0 = selamanya
+	PartitionsAhead        int  // jumlah partisi bulan depan yang disiapkan
+	Detach                 bool // true = partisi lama hanya di-detach, bukan di-drop
+	Interval               time.Duration
+}
+
+type CheckLogRetentionUseCase interface {
+	Start(ctx context.Context) error
+	RunOnce(ctx context.Context) error
+	Shutdown(ctx context.Context) error
+}
+
+type checkLogRetentionUseCase struct {
+	partitionRepo repository.PaymentRecordCheckLogPartitionRepository
+	config        CheckLogRetentionConfig
+	wg            sync.WaitGroup
+	logger        zerolog.Logger
+}
+
+func NewCheckLogRetentionUseCase(
+	partitionRepo repository.PaymentRecordCheckLogPartitionRepository,
+	config CheckLogRetentionConfig,
+	logger zerolog.Logger) CheckLogRetentionUseCase {
+	return &checkLogRetentionUseCase{
+		partitionRepo: partitionRepo,
+		config:        config,
+		logger:        logger,
+	}
+}
+
+// Start menjalankan job retention sekali di awal, lalu berkala tiap Interval.
+func (c *checkLogRetentionUseCase) Start(ctx context.Context) error {
+	if c.config.Interval <= 0 {
+		return errors.New("check log retention interval must be positive")
+	}
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		ticker := time.NewTicker(c.config.Interval)
+		defer ticker.Stop()
+
+		for {
+			if err := c.RunOnce(ctx); err != nil && ctx.Err() == nil {
+				c.logger.Error().Err(err).Msg("❌ Check log retention failed")
+			}
+
+			select {
+			case <-ctx.Done():
+				c.logger.Info().Msg("⁉️ Check log retention stopped")
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return nil
+}
+
+// RunOnce menyiapkan partisi bulan ini + PartitionsAhead bulan ke depan, lalu membuang partisi
+// yang seluruh isinya lebih tua dari RetentionDays (setelah percobaan terakhir payment final diarsip).
+// Replica lain yang sedang menjalankan job → dilewati.
+func (c *checkLogRetentionUseCase) RunOnce(ctx context.Context) error {
+	unlock, ok, err := c.partitionRepo.TryLock(ctx)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		c.logger.Debug().Msg("⏭️ Check log retention running on another instance")
+		return nil
+	}
+	defer unlock()
+
+	now := time.Now().UTC()
+	for _, partition := range entity.UpcomingCheckLogPartitions(now, c.config.PartitionsAhead) {
+		created, err := c.partitionRepo.EnsurePartition(ctx, partition)
+		if err != nil {
+			return err
+		}
+		if created {
+			c.logger.Info().Msgf("🧱 Check log partition created: %s", partition.Name)
+		}
+	}
+
+	if c.config.RetentionDays > 0 {
+		if err := c.purgeCheckLogs(ctx, now.AddDate(0, 0, -c.config.RetentionDays)); err != nil {
+			return err
+		}
+	}
+
+	if c.config.FinalizedRetentionDays > 0 {
+		purged, err := c.partitionRepo.PurgeArchives(ctx, now.AddDate(0, 0, -c.config.FinalizedRetentionDays))
+		if err != nil {
+			return err
+		}
+		if purged > 0 {
+			c.logger.Info().Int64("count", purged).Msg("🧹 Purged archived check logs of finalized payments")
+		}
+	}
+	return nil
+}
+
+func (c *checkLogRetentionUseCase) purgeCheckLogs(ctx context.Context, cutoff time.Time) error {
+	partitions, err := c.partitionRepo.ListPartitions(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, partition := range partitions {
+		if partition.To.After(cutoff) {
+			continue
+		}
+		archived, err := c.partitionRepo.ArchiveLastAttempts(ctx, partition)
+		if err != nil {
+			return err
+		}
+		if err := c.partitionRepo.RemovePartition(ctx, partition, c.config.Detach); err != nil {
+			return err
+		}
+
+		action := "dropped"
+		if c.config.Detach {
+			action = "detached"
+		}
+		c.logger.Info().Int64("archived", archived).Msgf("🧹 Check log partition %s: %s", action, partition.Name)
+	}
+
+	purged, err := c.partitionRepo.PurgeDefaultPartition(ctx, cutoff)
+	if err != nil {
+		return err
+	}
+	if purged > 0 {
+		c.logger.Info().Int64("count", purged).Msg("🧹 Purged old check logs from default partition")
+	}
+	return nil
+}
+
+// Shutdown menunggu job retention yang sedang berjalan selesai (ctx Start harus sudah dibatalkan).
+func (c *checkLogRetentionUseCase) Shutdown(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		c.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -3,10 +3,17 @@ package usecase
 import (
 	"beta-payment-api-client/internal/contextkeys"
 	"beta-payment-api-client/internal/entity"
+	"beta-payment-api-client/internal/pkg/circuitbreaker"
+	"beta-payment-api-client/internal/pkg/metrics"
+	"beta-payment-api-client/internal/pkg/payment_provider"
+	pkgPaymentServer "beta-payment-api-client/internal/pkg/payment_server"
+	"beta-payment-api-client/internal/pkg/scheduler"
 	"beta-payment-api-client/internal/repository"
+	"beta-payment-api-client/internal/valueobject"
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"github.com/google/uuid"
 	"github.com/rs/zerolog"
 	"math/rand"
@@ -17,14 +24,57 @@ import (
 
 var seen sync.Map
 
+var (
+	ErrInvalidPollingPolicy = errors.New("invalid polling policy")
+	ErrPollingQueueFull     = scheduler.ErrQueueFull
+	ErrPollingTaskNotFound  = repository.ErrPollingTaskNotFound
+	ErrPollingTaskNotOwned  = errors.New("polling task is owned by another instance")
+	ErrPaymentFinalized     = errors.New("payment record already finalized")
+	ErrUnknownProvider      = payment_provider.ErrUnknownProvider
+	ErrForeignReplayURL     = pkgPaymentServer.ErrForeignReplayURL
+
+	// errTaskReleased: task dilepas dari instance ini sebelum boost sempat dicek
+	errTaskReleased = errors.New("polling task released before boosted check")
+)
+
+const (
+	// providerAuthPauseCooldown lama pause provider setelah kredensial ditolak sebelum dicoba lagi (probe);
+	// providerProbeTimeout batas menunggu hasil probe sebelum replica melepas probe berikutnya
+	providerAuthPauseCooldown = 5 * time.Minute
+	providerProbeTimeout      = time.Minute
+	// notFoundConfirmations jumlah 404 berturut-turut sebelum payment dianggap tidak ada di payment server
+	notFoundConfirmations = 3
+
+	boostAttempts = 2
+	// boostForwardTimeout batas replica pemilik menunggu cek hasil boost yang diteruskan;
+	// sama dengan batas waktu handler boost
+	boostForwardTimeout = 30 * time.Second
+)
+
 type PaymentRecordUseCase interface {
-	StartPolling(ctx context.Context, id uuid.UUID) error
+	StartPolling(ctx context.Context, id uuid.UUID, tag, provider string, override *entity.PollingPolicyOverride) error
+	ValidatePolling(tag, provider string, override *entity.PollingPolicyOverride) error
 	StartConsumer(ctx context.Context) error
+	StartScheduler(ctx context.Context) error
+	StartLeaseKeeper(ctx context.Context) error
+	StartControlListener(ctx context.Context) error
 	BoostOtherTasks(id uuid.UUID) error
+	BoostTask(ctx context.Context, id uuid.UUID) (*entity.PollingTaskInfo, error)
 	Create(ctx context.Context, paymentRecord entity.PaymentRecord) (*entity.PaymentRecord, error)
 	GetByID(ctx context.Context, id uuid.UUID) (*entity.PaymentRecord, error)
+	UpdateStatus(ctx context.Context, id uuid.UUID, status entity.PaymentStatus) error
 	ListRunningTasks() []uuid.UUID
+	ListTasks(ctx context.Context, filter entity.PollingTaskFilter) ([]entity.PollingTaskInfo, int, error)
+	GetTask(ctx context.Context, id uuid.UUID) (*entity.PollingTaskInfo, error)
+	ListCheckHistories(ctx context.Context, filter entity.CheckHistoryFilter) ([]entity.PaymentRecordCheckAttempt, int, string, error)
+	CancelTask(ctx context.Context, id uuid.UUID) error
+	CancelTasksByTag(ctx context.Context, tag string) ([]uuid.UUID, error)
+	PauseTask(ctx context.Context, id uuid.UUID) error
+	ResumeTask(ctx context.Context, id uuid.UUID) error
+	PauseAll(ctx context.Context) error
+	ResumeAll(ctx context.Context) error
 	RestorePollingTasks(ctx context.Context) error
+	Shutdown(ctx context.Context) error
 	DebugDumpTasks()
 }
 
@@ -32,12 +82,149 @@ type taskHandle struct {
 	ctx    context.Context
 	cancel context.CancelFunc
 	wake   chan struct{} // sinyal boost/reset delay
+	task   entity.PollingTask
+	delay  time.Duration // delay backoff saat ini; 0 = belum pernah dicek
+	// pausedAt: kapan task mulai di-pause (zero = tidak di-pause);
+	// dipakai untuk menggeser deadline supaya waktu pause tidak dihitung
+	pausedAt time.Time
+
+	mu          sync.Mutex
+	waiters     []chan entity.PollingTaskInfo // menunggu hasil cek berikutnya (boost per task)
+	closed      bool                          // task selesai / dilepas: tidak menerima waiter baru
+	persistedAt time.Time                     // pertama kali state task tersimpan di Redis; zero = belum
+}
+
+// pauseCache salinan lokal state pause di Redis supaya tiap cek tidak perlu round-trip;
+// diperbarui lewat pub/sub polling_control dan disinkron ulang setiap heartbeat lease keeper.
+type pauseCache struct {
+	mu     sync.RWMutex
+	state  entity.PollingPauseState
+	probes map[string]time.Time // provider → kapan probe kredensial terakhir dilepas di replica ini
+	gen    uint64               // naik setiap perubahan lewat pesan; sinkron ulang yang lebih lama tidak boleh menimpa
+}
+
+func (c *pauseCache) paused(id uuid.UUID, provider string) bool {
+	now := time.Now()
+	c.mu.RLock()
+	paused := c.state.Paused(id, provider, now)
+	_, providerPaused := c.state.Providers[provider]
+	c.mu.RUnlock()
+	if paused || !providerPaused {
+		return paused
+	}
+
+	// Cooldown pause provider sudah lewat: satu cek per replica dilepas sebagai probe kredensial,
+	// cek lain tetap di-pause sampai probe berhasil (resume) atau ditolak lagi (pause diperpanjang)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.state.Providers[provider]; !ok {
+		return false
+	}
+	if now.Before(c.probes[provider].Add(providerProbeTimeout)) {
+		return true
+	}
+	if c.probes == nil {
+		c.probes = make(map[string]time.Time)
+	}
+	c.probes[provider] = now
+	return false
+}
+
+// providerCooldownOver true jika provider sedang di-pause dan cooldown-nya sudah lewat (cek ini adalah probe).
+func (c *pauseCache) providerCooldownOver(provider string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	until, ok := c.state.Providers[provider]
+	return ok && !time.Now().Before(until)
+}
+
+func (c *pauseCache) generation() uint64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.gen
+}
+
+// setAll mengubah pause global; resume global ikut menghapus pause per provider.
+func (c *pauseCache) setAll(paused bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.state.All = paused
+	if !paused {
+		c.state.Providers = nil
+		c.probes = nil
+	}
+	c.gen++
+}
+
+func (c *pauseCache) setTask(id uuid.UUID, paused bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.state.Tasks == nil {
+		c.state.Tasks = make(map[uuid.UUID]bool)
+	}
+	if paused {
+		c.state.Tasks[id] = true
+	} else {
+		delete(c.state.Tasks, id)
+	}
+	c.gen++
+}
+
+func (c *pauseCache) setProvider(provider string, until time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.state.Providers == nil {
+		c.state.Providers = make(map[string]time.Time)
+	}
+	c.state.Providers[provider] = until
+	delete(c.probes, provider)
+	c.gen++
+}
+
+func (c *pauseCache) resumeProvider(provider string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.state.Providers, provider)
+	delete(c.probes, provider)
+	c.gen++
+}
+
+// replace mengganti seluruh isi cache dengan state Redis yang dibaca saat generasi gen.
+// Probe yang sedang berjalan dipertahankan supaya sinkron ulang tidak melepas probe kedua.
+func (c *pauseCache) replace(gen uint64, state entity.PollingPauseState) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.gen != gen {
+		return false
+	}
+	c.state = state
+	for provider := range c.probes {
+		if _, ok := state.Providers[provider]; !ok {
+			delete(c.probes, provider)
+		}
+	}
+	return true
+}
+
+// PollingLeaseConfig mengatur kepemilikan task antar replica lewat lease Redis.
+type PollingLeaseConfig struct {
+	InstanceID string
+	TTL        time.Duration
+	Heartbeat  time.Duration
 }
 
 type paymentRecordUseCase struct {
 	paymentRecordRepo         repository.PaymentRecordRepository
 	paymentRecordCheckLogRepo repository.PaymentRecordCheckLogRepository
+	paymentProviders          *payment_provider.Registry
+	statusMappings            entity.StatusMappings
 	tasks                     sync.Map
+	pollingPolicies           entity.PollingPolicySet
+	scheduler                 *scheduler.Scheduler
+	lease                     PollingLeaseConfig
+	pause                     pauseCache
+	boostRequests             sync.Map       // request id boost yang diteruskan → chan balasan
+	wg                        sync.WaitGroup // goroutine background: lease keeper, control listener + Kafka consumer
 	db                        *sql.DB
 	logger                    zerolog.Logger
 }
@@ -45,168 +232,682 @@ type paymentRecordUseCase struct {
 func NewPaymentRecordUseCase(
 	paymentRecordRepo repository.PaymentRecordRepository,
 	paymentRecordCheckLogRepo repository.PaymentRecordCheckLogRepository,
+	paymentProviders *payment_provider.Registry,
+	statusMappings entity.StatusMappings,
+	pollingPolicies entity.PollingPolicySet,
+	scheduler *scheduler.Scheduler,
+	lease PollingLeaseConfig,
 	db *sql.DB,
 	logger zerolog.Logger) PaymentRecordUseCase {
 	return &paymentRecordUseCase{
 		paymentRecordRepo:         paymentRecordRepo,
 		paymentRecordCheckLogRepo: paymentRecordCheckLogRepo,
+		paymentProviders:          paymentProviders,
+		statusMappings:            statusMappings,
+		pollingPolicies:           pollingPolicies,
+		scheduler:                 scheduler,
+		lease:                     lease,
 		db:                        db,
 		logger:                    logger,
 	}
 }
 
-func (paymentRecordUC *paymentRecordUseCase) StartPollingYangLama(ctx context.Context, id uuid.UUID) error {
-	go func() {
-		delay := 10 * time.Second
-		maxDelay := 60 * time.Second
+func (paymentRecordUC *paymentRecordUseCase) StartPolling(ctx context.Context, id uuid.UUID, tag, provider string, override *entity.PollingPolicyOverride) error {
+	policy, provider, err := paymentRecordUC.resolvePolling(tag, provider, override)
+	if err != nil {
+		return err
+	}
+	return paymentRecordUC.startTask(ctx, entity.PollingTask{ID: id, Tag: tag, Provider: provider, Policy: policy}, false)
+}
 
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			default:
-				paymentRecordUC.logger.Info().Msgf("⚓️ Polling Payment Record with id: %s", id)
-				status, paymentRecordCheckHTTP, err := paymentRecordUC.paymentRecordRepo.FetchPaymentStatus(
-					context.WithValue(ctx, contextkeys.CtxKeyPollingDelay, delay),
-					id,
-				)
+// ValidatePolling mengecek policy hasil gabungan (default + tag + override) dan provider
+// sebelum ada yang disimpan, supaya request invalid tidak meninggalkan payment record tanpa polling.
+func (paymentRecordUC *paymentRecordUseCase) ValidatePolling(tag, provider string, override *entity.PollingPolicyOverride) error {
+	_, _, err := paymentRecordUC.resolvePolling(tag, provider, override)
+	return err
+}
 
-				err = paymentRecordUC.paymentRecordCheckLogRepo.LogFetchAttempt(paymentRecordCheckHTTP, delay)
+func (paymentRecordUC *paymentRecordUseCase) resolvePolling(tag, provider string, override *entity.PollingPolicyOverride) (entity.PollingPolicy, string, error) {
+	policy := paymentRecordUC.pollingPolicies.Resolve(tag, override)
+	if err := policy.Validate(); err != nil {
+		return entity.PollingPolicy{}, "", fmt.Errorf("%w: %v", ErrInvalidPollingPolicy, err)
+	}
+	provider, err := paymentRecordUC.paymentProviders.Resolve(provider, tag)
+	if err != nil {
+		return entity.PollingPolicy{}, "", err
+	}
+	return policy, provider, nil
+}
 
-				if err != nil {
-					paymentRecordUC.logger.Error().Msgf("❌ Error fetching Payment Record with: %s", err)
-				}
+// startTask menjalankan task di instance ini; restored=true untuk task yang dibaca dari state Redis.
+func (paymentRecordUC *paymentRecordUseCase) startTask(ctx context.Context, task entity.PollingTask, restored bool) error {
+	id := task.ID
+	key := id.String()
 
-				if status == "PAID" || status == "UNPAID" {
-					paymentRecordUC.logger.Info().Msgf("️🔄 Finalized: %s -> %s", id, status)
-					_ = paymentRecordUC.paymentRecordRepo.PublishSuccessEvent(ctx, id)
+	if task.StartedAt.IsZero() {
+		task.StartedAt = time.Now()
+	}
 
-					// ✅ Remove from in-memory tasks
-					paymentRecordUC.tasks.Delete(id)
+	// Buat handle + simpan; cegah worker ganda.
+	// Umur task hanya diatur stopTask/finishTask: saat shutdown, cek yang sedang jalan tetap diselesaikan.
+	wctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	h := &taskHandle{
+		ctx:    wctx,
+		cancel: cancel,
+		wake:   make(chan struct{}, 1), // buffered agar non-blocking
+		task:   task,
+		delay:  task.Delay.Duration, // task hasil restore melanjutkan backoff terakhir
+	}
+	if _, loaded := paymentRecordUC.tasks.LoadOrStore(key, h); loaded {
+		cancel()
+		paymentRecordUC.logger.Info().Msgf("⏭️ Task already running: %s", id)
+		return nil
+	}
 
-					// ✅ Remove from Redis persistence
-					_ = paymentRecordUC.paymentRecordRepo.RemovePollingTask(ctx, id)
-					return
-				}
+	// Hanya satu replica yang boleh mem-poll payment ini
+	acquired, err := paymentRecordUC.paymentRecordRepo.AcquirePollingLease(ctx, id, paymentRecordUC.lease.InstanceID, paymentRecordUC.lease.TTL)
+	if err != nil || !acquired {
+		paymentRecordUC.tasks.Delete(key)
+		cancel()
+		if err != nil {
+			paymentRecordUC.logger.Error().Err(err).Msgf("❌ Failed to acquire polling lease: %s", id)
+			return err
+		}
+		paymentRecordUC.logger.Debug().Msgf("⏭️ Task owned by another instance: %s", id)
+		return nil
+	}
 
-				_ = paymentRecordUC.paymentRecordRepo.SetNextRetry(ctx, id, delay)
-				time.Sleep(delay)
+	// Persist marker aktif + policy sebelum cek pertama dijadwalkan, supaya persist ini
+	// tidak menimpa RemovePollingTask dari cek yang langsung selesai.
+	// Task restore hanya diperbarui: jika sudah dibatalkan di replica lain, jangan dihidupkan lagi.
+	if restored {
+		if !paymentRecordUC.persistTask(h) {
+			return nil
+		}
+	} else if err := paymentRecordUC.paymentRecordRepo.PersistPollingTask(ctx, task); err == nil {
+		h.markPersisted()
+	}
 
-				if delay < maxDelay {
-					delay *= 2
-				}
-			}
+	// Jadwalkan cek pertama sekarang; tolak jika antrean scheduler penuh
+	if err := paymentRecordUC.scheduler.Schedule(key, 0); err != nil {
+		paymentRecordUC.tasks.Delete(key)
+		// Task baru tidak boleh tertinggal di Redis; task restore tetap di sana untuk diambil replica lain
+		if !restored {
+			_ = paymentRecordUC.paymentRecordRepo.RemovePollingTask(ctx, id)
 		}
-	}()
-	paymentRecordUC.tasks.Store(id, true)
-	_ = paymentRecordUC.paymentRecordRepo.PersistPollingTask(ctx, id)
-	paymentRecordUC.DebugDumpTasks()
+		_ = paymentRecordUC.paymentRecordRepo.ReleasePollingLease(ctx, id, paymentRecordUC.lease.InstanceID)
+		cancel()
+		paymentRecordUC.logger.Warn().Err(err).Msgf("‼️ Cannot schedule polling task: %s", id)
+		return err
+	}
 	return nil
 }
 
-func (paymentRecordUC *paymentRecordUseCase) StartPolling(ctx context.Context, id uuid.UUID) error {
-	paymentRecordUC.logger.Info().Msgf("[StartPolling] paymentRecordUC=%p id=%s", paymentRecordUC, id)
-	key := id.String()
+// persistTask menyimpan state task yang sedang berjalan. Task yang sudah dihapus dari Redis
+// (dibatalkan / diselesaikan replica lain) dilepas dan tidak ditulis ulang; false jika begitu.
+// Gagal menulis ke Redis tidak menghentikan task: cek berikutnya mencoba lagi.
+func (paymentRecordUC *paymentRecordUseCase) persistTask(h *taskHandle) bool {
+	id := h.task.ID
+	updated, err := paymentRecordUC.paymentRecordRepo.UpdatePollingTask(h.ctx, h.task)
+	if err != nil {
+		paymentRecordUC.logger.Warn().Err(err).Msgf("‼️ Failed to persist polling task: %s", id)
+		return true
+	}
+	if !updated {
+		paymentRecordUC.logger.Info().Msgf("🛑 Polling task removed from Redis, stopping: %s", id)
+		paymentRecordUC.releaseTask(h)
+		return false
+	}
+	h.markPersisted()
+	return true
+}
 
-	// Cegah worker ganda
-	if _, loaded := paymentRecordUC.tasks.Load(key); loaded {
-		paymentRecordUC.logger.Info().Msgf("⏭️ Task already running: %s", id)
-		return nil
+// markPersisted mencatat kapan state task pertama kali tersimpan di Redis.
+func (h *taskHandle) markPersisted() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.persistedAt.IsZero() {
+		h.persistedAt = time.Now()
 	}
+}
 
-	// Buat handle + simpan
-	wctx, cancel := context.WithCancel(ctx)
-	h := &taskHandle{
-		ctx:    wctx,
-		cancel: cancel,
-		wake:   make(chan struct{}, 1), // buffered agar non-blocking
+// persistedBefore true jika state task sudah tersimpan di Redis sebelum t.
+func (h *taskHandle) persistedBefore(t time.Time) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return !h.persistedAt.IsZero() && h.persistedAt.Before(t)
+}
+
+// addWaiter mendaftarkan waiter untuk cek berikutnya; false jika task sudah selesai / dilepas.
+func (h *taskHandle) addWaiter(waiter chan entity.PollingTaskInfo) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return false
 	}
-	paymentRecordUC.tasks.Store(key, h)
+	h.waiters = append(h.waiters, waiter)
+	return true
+}
 
-	// Persist marker aktif (opsional)
-	_ = paymentRecordUC.paymentRecordRepo.PersistPollingTask(ctx, id)
+func (h *taskHandle) takeWaiters() []chan entity.PollingTaskInfo {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	waiters := h.waiters
+	h.waiters = nil
+	return waiters
+}
 
-	// Mulai worker
-	go paymentRecordUC.pollWorker(h, id)
+// closeWaiters menutup handle untuk waiter baru dan mengembalikan waiter yang masih menunggu.
+func (h *taskHandle) closeWaiters() []chan entity.PollingTaskInfo {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	waiters := h.waiters
+	h.waiters = nil
+	return waiters
+}
 
+func (paymentRecordUC *paymentRecordUseCase) StartScheduler(ctx context.Context) error {
+	paymentRecordUC.scheduler.Start(ctx, paymentRecordUC.runTask)
 	return nil
 }
 
-func (paymentRecordUC *paymentRecordUseCase) pollWorker(h *taskHandle, id uuid.UUID) {
-	key := id.String()
-	delay := 10 * time.Second
-	maxDelay := 80 * time.Second // sesuai ekspektasi kamu
+// runTask dijalankan worker scheduler setiap kali task jatuh tempo: satu kali cek,
+// lalu mengembalikan delay sampai cek berikutnya (done=true jika task selesai).
+func (paymentRecordUC *paymentRecordUseCase) runTask(_ context.Context, key string) (time.Duration, bool) {
+	v, ok := paymentRecordUC.tasks.Load(key)
+	if !ok {
+		return 0, true
+	}
+	h := v.(*taskHandle)
+	// Hanya waiter yang mendaftar sebelum cek ini dimulai yang menerima hasilnya;
+	// boost yang masuk selama cek berjalan menunggu cek berikutnya (hasil boost-nya sendiri)
+	waiters := h.takeWaiters()
+	next, done := paymentRecordUC.checkTask(h)
+	paymentRecordUC.notifyWaiters(h, waiters, next, done)
+	return next, done
+}
+
+// notifyWaiters mengirim hasil cek ke pemanggil BoostTask yang sedang menunggu.
+func (paymentRecordUC *paymentRecordUseCase) notifyWaiters(h *taskHandle, waiters []chan entity.PollingTaskInfo, next time.Duration, done bool) {
+	if len(waiters) == 0 {
+		return
+	}
+
+	info := entity.PollingTaskInfo{
+		PollingTask: h.task,
+		Owner:       paymentRecordUC.lease.InstanceID,
+		Paused:      !h.pausedAt.IsZero(),
+	}
+	if !done {
+		nextRetry := time.Now().Add(next)
+		info.NextRetryAt = &nextRetry
+	}
+	for _, waiter := range waiters {
+		waiter <- info
+	}
+}
+
+func (paymentRecordUC *paymentRecordUseCase) checkTask(h *taskHandle) (time.Duration, bool) {
+	key := h.task.ID.String()
+	id := h.task.ID
+	policy := h.task.Policy
+
+	if h.ctx.Err() != nil {
+		paymentRecordUC.tasks.Delete(key)
+		return 0, true
+	}
 
-	for {
-		// 1) Cek sekarang
-		paymentRecordUC.logger.Info().Msgf("⚓️ Polling Payment Record with id: %s", id)
+	// Pause (global / per task): jangan cek, tapi tetap terdaftar & state Redis utuh
+	if paymentRecordUC.isPaused(h) {
+		if h.pausedAt.IsZero() {
+			h.pausedAt = time.Now()
+			paymentRecordUC.logger.Info().Msgf("⏸️ Polling paused: %s", id)
+		}
+		return policy.InitialDelay.Duration, false
+	}
+	if !h.pausedAt.IsZero() {
+		h.task.StartedAt = h.task.StartedAt.Add(time.Since(h.pausedAt))
+		h.pausedAt = time.Time{}
+		paymentRecordUC.logger.Info().Msgf("▶️ Polling resumed: %s", id)
+	}
+
+	// BOOST: reset delay ke BoostResetDelay; selain itu exponential backoff sesuai policy
+	prevDelay := h.delay
+	select {
+	case <-h.wake:
+		h.delay = policy.BoostResetDelay.Duration
+	default:
+		if h.delay == 0 {
+			h.delay = policy.InitialDelay.Duration
+		} else {
+			h.delay = policy.Next(h.delay)
+		}
+	}
+	delay := h.delay
+
+	// 0) Sudah lewat deadline / attempt habis?
+	if h.task.Exhausted(time.Now()) {
+		return paymentRecordUC.timeoutTask(h)
+	}
 
-		status, paymentRecordCheckHTTP, fetchErr := paymentRecordUC.paymentRecordRepo.FetchPaymentStatus(
-			context.WithValue(h.ctx, contextkeys.CtxKeyPollingDelay, delay),
-			id,
-		)
+	// 1) Cek sekarang
+	paymentRecordUC.logger.Info().Msgf("⚓️ Polling Payment Record with id: %s", id)
 
-		if logErr := paymentRecordUC.paymentRecordCheckLogRepo.LogFetchAttempt(paymentRecordCheckHTTP, delay); logErr != nil {
-			paymentRecordUC.logger.Error().Msgf("❌ LogFetchAttempt error: %v", logErr)
+	provider, err := paymentRecordUC.paymentProviders.Get(h.task.Provider)
+	if err != nil {
+		// Provider dihapus dari konfigurasi → jangan poll ke provider lain; coba lagi dengan jeda maksimum
+		paymentRecordUC.logger.Error().Err(err).Msgf("❌ No provider for payment %s", id)
+		h.task.LastError = err.Error()
+		if !paymentRecordUC.persistTask(h) {
+			return 0, true
 		}
-		if fetchErr != nil {
-			paymentRecordUC.logger.Error().Msgf("❌ FetchPaymentStatus error: %v", fetchErr)
+		return policy.MaxDelay.Duration, false
+	}
+
+	rawStatus, paymentRecordCheckHTTP, fetchErr := provider.FetchStatus(
+		context.WithValue(h.ctx, contextkeys.CtxKeyPollingDelay, delay),
+		id,
+	)
+
+	// Circuit breaker open: request tidak dikirim → bukan attempt dan tidak ditulis ke check log.
+	// Tunda sampai breaker boleh dicoba lagi, tanpa memajukan backoff.
+	if errors.Is(fetchErr, circuitbreaker.ErrOpen) {
+		h.delay = prevDelay
+		wait := policy.WithJitter(circuitbreaker.RetryAfter(fetchErr))
+		if deadline := h.task.Deadline(); !deadline.IsZero() && time.Until(deadline) < wait {
+			wait = time.Until(deadline)
 		}
+		paymentRecordUC.logger.Debug().Msgf("🔌 Payment server circuit open, deferring %s for %s", id, wait)
+		return wait, false
+	}
+	h.task.Attempts++
 
-		// 2) Final?
-		if status == "PAID" || status == "UNPAID" {
-			paymentRecordUC.logger.Info().Msgf("️🔄 Finalized: %s -> %s", id, status)
-			_ = paymentRecordUC.paymentRecordRepo.PublishSuccessEvent(h.ctx, id)
+	if logErr := paymentRecordUC.paymentRecordCheckLogRepo.LogFetchAttempt(paymentRecordCheckHTTP, delay); logErr != nil {
+		paymentRecordUC.logger.Error().Msgf("❌ LogFetchAttempt error: %v", logErr)
+	}
+	if fetchErr != nil {
+		paymentRecordUC.logger.Error().Msgf("❌ FetchStatus error (%s): %v", provider.Name(), fetchErr)
+	}
 
-			// ❗❗ PENTING: JANGAN panggil BoostOtherTasks di sini.
-			// Biarkan Kafka consumer yang melakukan boost agar tidak double.
+	checkedAt := time.Now()
+	h.task.Delay = valueobject.Duration{Duration: delay}
+	h.task.LastCheckedAt = &checkedAt
+	h.task.LastRawStatus = rawStatus
+	h.task.LastError = ""
+	if fetchErr != nil {
+		h.task.LastError = fetchErr.Error()
+	}
 
-			// Cleanup
-			paymentRecordUC.tasks.Delete(key)
-			_ = paymentRecordUC.paymentRecordRepo.RemovePollingTask(h.ctx, id)
-			h.cancel()
-			return
+	// Provider sedang di-pause karena kredensial dan cek ini probe-nya: jawaban selain 401 berarti
+	// kredensial diterima lagi (error jaringan tidak membuktikan apa-apa, probe dicoba lagi nanti)
+	unauthorized := errors.Is(fetchErr, pkgPaymentServer.ErrUnauthorized)
+	if !unauthorized && paymentRecordCheckHTTP != nil && paymentRecordCheckHTTP.StatusCode != 0 &&
+		paymentRecordUC.pause.providerCooldownOver(h.task.Provider) {
+		paymentRecordUC.resumeProvider(h.ctx, h.task.Provider)
+	}
+	if !errors.Is(fetchErr, pkgPaymentServer.ErrNotFound) {
+		h.task.NotFoundCount = 0
+	}
+
+	// Klasifikasi error payment server: hanya error jaringan / 5xx yang ikut backoff normal
+	retryAfter := time.Duration(0)
+	switch {
+	case errors.Is(fetchErr, pkgPaymentServer.ErrNotFound):
+		h.task.NotFoundCount++
+		if h.task.NotFoundCount >= notFoundConfirmations {
+			// Payment tetap tidak dikenal payment server → tidak akan pernah final, hentikan polling
+			paymentRecordUC.notFoundTask(h)
+			return 0, true
 		}
+		// Payment baru bisa belum terlihat di payment server (replikasi tertunda): ulangi dengan backoff normal
+		paymentRecordUC.logger.Warn().Msgf("🔍 Payment %s not found on payment server (%d/%d), retrying", id, h.task.NotFoundCount, notFoundConfirmations)
+	case unauthorized:
+		// Kredensial ditolak (token baru pun ditolak) → cek lain ke provider yang sama juga akan gagal;
+		// pause provider ini saja, dicoba lagi lewat probe setelah cooldown
+		paymentRecordUC.logger.Error().
+			Str("alert", "payment_server_auth").
+			Str("provider", h.task.Provider).
+			Err(fetchErr).
+			Msgf("🚨 Payment server rejected our credentials, pausing polling tasks of provider %s for %s", h.task.Provider, providerAuthPauseCooldown)
+		paymentRecordUC.pauseProvider(h.ctx, h.task.Provider)
+	case errors.Is(fetchErr, pkgPaymentServer.ErrForbidden):
+		// Akses ke payment ini saja yang ditolak → task lain di provider yang sama tetap jalan;
+		// task ini dicoba lagi dengan jeda maksimum (izin bisa diperbaiki di sisi provider)
+		paymentRecordUC.logger.Warn().
+			Str("provider", h.task.Provider).
+			Err(fetchErr).
+			Msgf("⛔ Payment server denied access to payment %s, retrying in %s", id, policy.MaxDelay.Duration)
+		retryAfter = policy.MaxDelay.Duration
+	case errors.Is(fetchErr, pkgPaymentServer.ErrThrottled):
+		retryAfter = pkgPaymentServer.RetryAfter(fetchErr)
+	case errors.Is(fetchErr, pkgPaymentServer.ErrMalformedResponse),
+		errors.Is(fetchErr, pkgPaymentServer.ErrUnexpectedStatus):
+		// Bukan error transien: retry secepat backoff tidak akan membantu, pakai jeda maksimum
+		retryAfter = policy.MaxDelay.Duration
+	}
 
-		// 3) Simpan informasi next retry (opsional)
-		_ = paymentRecordUC.paymentRecordRepo.SetNextRetry(h.ctx, id, delay)
+	// Status mentah provider dipetakan ke status kanonik; status tak dikenal dianggap non-terminal
+	var rule entity.StatusMappingRule
+	if fetchErr == nil {
+		mapped, known := paymentRecordUC.statusMappings.Map(h.task.Provider, rawStatus)
+		if !known {
+			paymentRecordUC.logger.Warn().
+				Str("provider", h.task.Provider).
+				Str("raw_status", rawStatus).
+				Msgf("‼️ Unknown status %q from %s for %s, treating as non-terminal", rawStatus, h.task.Provider, id)
+			metrics.UnknownPaymentStatuses.Add(h.task.Provider+":"+rawStatus, 1)
+			h.task.LastError = fmt.Sprintf("unknown payment status: %q", rawStatus)
+		}
+		rule = mapped
+		h.task.LastStatus = string(rule.Status)
+	}
+	status := rule.Status
 
-		// 4) Tunggu dengan timer yang bisa di-reset
-		timer := time.NewTimer(delay)
-		select {
-		case <-h.ctx.Done():
-			if !timer.Stop() {
-				<-timer.C
+	// 2) Final?
+	if rule.Terminal {
+		// Simpan status final ke DB dulu; kalau gagal, task tetap jalan dan dicoba lagi di cek berikutnya
+		if err := paymentRecordUC.updateStatus(h.ctx, id, status); err != nil {
+			if statusUpdateStopsTask(err) {
+				// Record sudah final dengan status lain (mis. CANCELLED) atau sudah dihapus → hentikan polling tanpa event
+				paymentRecordUC.logger.Warn().Err(err).Msgf("‼️ Cannot finalize %s as %s, stopping polling", id, status)
+				paymentRecordUC.finishTask(h)
+				return 0, true
 			}
-			paymentRecordUC.tasks.Delete(key)
-			return
+			paymentRecordUC.logger.Error().Err(err).Msgf("❌ Failed to persist final status %s for %s", status, id)
+			return policy.WithJitter(delay), false
+		}
 
-		case <-h.wake:
-			// BOOST: stop timer lama, reset delay ke 10s, cek ulang
-			if !timer.Stop() {
-				<-timer.C
-			}
-			delay = 10 * time.Second
-			continue
+		paymentRecordUC.logger.Info().Msgf("️🔄 Finalized: %s -> %s", id, status)
+		_ = paymentRecordUC.paymentRecordRepo.PublishSuccessEvent(h.ctx, id)
 
-		case <-timer.C:
-			// timeout normal → exponential backoff
-			if delay < maxDelay {
-				delay *= 2
-				if delay > maxDelay {
-					delay = maxDelay
-				}
-			}
-			// loop lagi → cek lagi
+		// ❗❗ PENTING: JANGAN panggil BoostOtherTasks di sini.
+		// Biarkan Kafka consumer yang melakukan boost agar tidak double.
+
+		paymentRecordUC.finishTask(h)
+		return 0, true
+	}
+
+	if h.task.Exhausted(time.Now()) {
+		return paymentRecordUC.timeoutTask(h)
+	}
+
+	// Task dibatalkan saat sedang dicek → jangan persist ulang ke Redis
+	if h.ctx.Err() != nil {
+		return 0, true
+	}
+
+	// 3) Simpan attempt + informasi next retry (opsional)
+	if !paymentRecordUC.persistTask(h) {
+		return 0, true
+	}
+	wait := policy.WithJitter(delay)
+	if wait < retryAfter {
+		wait = retryAfter
+	}
+	if deadline := h.task.Deadline(); !deadline.IsZero() && time.Until(deadline) < wait {
+		wait = time.Until(deadline)
+	}
+	_ = paymentRecordUC.paymentRecordRepo.SetNextRetry(h.ctx, id, wait)
+
+	// 4) Serahkan ke scheduler; boost (Wake) bisa memajukan jadwal ini
+	return wait, false
+}
+
+func (paymentRecordUC *paymentRecordUseCase) isPaused(h *taskHandle) bool {
+	return paymentRecordUC.pause.paused(h.task.ID, h.task.Provider)
+}
+
+// pauseProvider mem-pause semua task satu provider (kredensial ditolak) di semua replica;
+// setelah providerAuthPauseCooldown satu cek dilepas sebagai probe kredensial.
+func (paymentRecordUC *paymentRecordUseCase) pauseProvider(ctx context.Context, provider string) {
+	until := time.Now().Add(providerAuthPauseCooldown)
+	if err := paymentRecordUC.paymentRecordRepo.SetPollingProviderPaused(ctx, provider, until); err != nil {
+		paymentRecordUC.logger.Error().Err(err).Msgf("❌ Failed to pause polling tasks of provider %s", provider)
+	}
+	paymentRecordUC.pause.setProvider(provider, until)
+	paymentRecordUC.broadcastControl(ctx, entity.PollingControl{Action: entity.PollingControlPauseProvider, Provider: provider, Until: until})
+}
+
+// resumeProvider dipanggil saat probe diterima payment server: pause provider dicabut di semua replica.
+func (paymentRecordUC *paymentRecordUseCase) resumeProvider(ctx context.Context, provider string) {
+	paymentRecordUC.logger.Info().Msgf("▶️ Provider %s accepted our credentials again, resuming its polling tasks", provider)
+	if err := paymentRecordUC.paymentRecordRepo.ResumePollingProvider(ctx, provider); err != nil {
+		paymentRecordUC.logger.Error().Err(err).Msgf("❌ Failed to resume polling tasks of provider %s", provider)
+	}
+	paymentRecordUC.pause.resumeProvider(provider)
+	paymentRecordUC.broadcastControl(ctx, entity.PollingControl{Action: entity.PollingControlResumeProvider, Provider: provider})
+}
+
+// refreshPauseState menyinkronkan cache pause dengan Redis (startup + setiap heartbeat),
+// menutup celah pesan pub/sub yang hilang saat koneksi terputus.
+func (paymentRecordUC *paymentRecordUseCase) refreshPauseState(ctx context.Context) error {
+	gen := paymentRecordUC.pause.generation()
+	state, err := paymentRecordUC.paymentRecordRepo.FetchPauseStates(ctx)
+	if err != nil {
+		paymentRecordUC.logger.Warn().Err(err).Msg("‼️ Failed to read pause state")
+		return err
+	}
+	paymentRecordUC.pause.replace(gen, state)
+	return nil
+}
+
+// StartControlListener memuat state pause lalu mendengarkan pesan kontrol dari replica lain.
+func (paymentRecordUC *paymentRecordUseCase) StartControlListener(ctx context.Context) error {
+	_ = paymentRecordUC.refreshPauseState(ctx)
+
+	messages, err := paymentRecordUC.paymentRecordRepo.SubscribePollingControl(ctx)
+	if err != nil {
+		paymentRecordUC.logger.Error().Err(err).Msg("❌ Failed to subscribe polling control; pause state only synced on heartbeat")
+		return err
+	}
+
+	paymentRecordUC.wg.Add(1)
+	go func() {
+		defer paymentRecordUC.wg.Done()
+		for msg := range messages {
+			paymentRecordUC.handleControl(ctx, msg)
+		}
+		paymentRecordUC.logger.Info().Msg("⁉️ Control listener stopped")
+	}()
+	return nil
+}
+
+func (paymentRecordUC *paymentRecordUseCase) handleControl(ctx context.Context, msg entity.PollingControl) {
+	if msg.Origin == paymentRecordUC.lease.InstanceID {
+		return // sudah diterapkan saat dikirim
+	}
+	paymentRecordUC.logger.Debug().Msgf("📨 Polling control %s %s from %s", msg.Action, msg.ID, msg.Origin)
+
+	switch msg.Action {
+	case entity.PollingControlPauseAll:
+		paymentRecordUC.pause.setAll(true)
+	case entity.PollingControlResumeAll:
+		paymentRecordUC.pause.setAll(false)
+		paymentRecordUC.wakeAll()
+	case entity.PollingControlPause:
+		paymentRecordUC.pause.setTask(msg.ID, true)
+	case entity.PollingControlResume:
+		paymentRecordUC.pause.setTask(msg.ID, false)
+		paymentRecordUC.scheduler.Wake(msg.ID.String())
+	case entity.PollingControlPauseProvider:
+		paymentRecordUC.pause.setProvider(msg.Provider, msg.Until)
+	case entity.PollingControlResumeProvider:
+		paymentRecordUC.pause.resumeProvider(msg.Provider)
+	case entity.PollingControlBoost:
+		paymentRecordUC.handleBoost(ctx, msg)
+	case entity.PollingControlCancel:
+		if v, ok := paymentRecordUC.tasks.Load(msg.ID.String()); ok {
+			paymentRecordUC.logger.Info().Msgf("🛑 Polling task cancelled by %s: %s", msg.Origin, msg.ID)
+			paymentRecordUC.releaseTask(v.(*taskHandle))
+		}
+	case entity.PollingControlBoostResult:
+		if reply, ok := paymentRecordUC.boostRequests.LoadAndDelete(msg.RequestID); ok {
+			reply.(chan entity.PollingControl) <- msg
+		}
+	default:
+		paymentRecordUC.logger.Warn().Msgf("‼️ Unknown polling control action %q", msg.Action)
+	}
+}
+
+// broadcastControl menyiarkan perubahan ke replica lain; state Redis sudah ditulis sebelumnya,
+// jadi jika publish gagal replica lain tetap menyusul pada heartbeat berikutnya.
+func (paymentRecordUC *paymentRecordUseCase) broadcastControl(ctx context.Context, msg entity.PollingControl) {
+	msg.Origin = paymentRecordUC.lease.InstanceID
+	if err := paymentRecordUC.paymentRecordRepo.PublishPollingControl(ctx, msg); err != nil {
+		paymentRecordUC.logger.Warn().Err(err).Msgf("‼️ Failed to broadcast polling control %s", msg.Action)
+	}
+}
+
+func (paymentRecordUC *paymentRecordUseCase) wakeAll() {
+	paymentRecordUC.tasks.Range(func(k, _ any) bool {
+		paymentRecordUC.scheduler.Wake(k.(string))
+		return true
+	})
+}
+
+// timeoutTask menghentikan task yang melewati deadline / batas attempt:
+// status record → TIMED_OUT, kirim event Kafka, lalu bersihkan task.
+// Event hanya dikirim setelah TIMED_OUT tersimpan; kalau update gagal, dicoba lagi di jadwal berikutnya.
+func (paymentRecordUC *paymentRecordUseCase) timeoutTask(h *taskHandle) (time.Duration, bool) {
+	id := h.task.ID
+	paymentRecordUC.logger.Warn().
+		Str("payment_id", id.String()).
+		Int("attempts", h.task.Attempts).
+		Time("started_at", h.task.StartedAt).
+		Msgf("⏰ Polling timed out: %s", id)
+
+	if err := paymentRecordUC.updateStatus(h.ctx, id, entity.PaymentStatusTimedOut); err != nil {
+		if statusUpdateStopsTask(err) {
+			// Record sudah final (mis. CANCELLED) atau dihapus → tidak ada yang timeout, hentikan tanpa event
+			paymentRecordUC.logger.Warn().Err(err).Msgf("‼️ Cannot mark payment %s as TIMED_OUT, stopping polling", id)
+			paymentRecordUC.finishTask(h)
+			return 0, true
 		}
+		paymentRecordUC.logger.Error().Err(err).Msgf("❌ Failed to mark payment %s as TIMED_OUT, retrying", id)
+		return h.task.Policy.WithJitter(h.delay), false
+	}
+	_ = paymentRecordUC.paymentRecordRepo.PublishTimeoutEvent(h.ctx, id)
+
+	paymentRecordUC.finishTask(h)
+	return 0, true
+}
+
+// notFoundTask menghentikan task untuk payment yang tidak dikenal payment server:
+// status record → NOT_FOUND, lalu bersihkan task.
+func (paymentRecordUC *paymentRecordUseCase) notFoundTask(h *taskHandle) {
+	id := h.task.ID
+	paymentRecordUC.logger.Warn().
+		Str("payment_id", id.String()).
+		Int("attempts", h.task.Attempts).
+		Msgf("🔍 Payment not found on payment server: %s", id)
+
+	if err := paymentRecordUC.updateStatus(h.ctx, id, entity.PaymentStatusNotFound); err != nil {
+		paymentRecordUC.logger.Error().Err(err).Msgf("❌ Failed to mark payment %s as NOT_FOUND", id)
+	}
+
+	paymentRecordUC.finishTask(h)
+}
+
+// finishTask membersihkan task yang sudah selesai: in-memory, Redis, dan lease.
+func (paymentRecordUC *paymentRecordUseCase) finishTask(h *taskHandle) {
+	id := h.task.ID
+	paymentRecordUC.tasks.Delete(id.String())
+	_ = paymentRecordUC.paymentRecordRepo.RemovePollingTask(h.ctx, id)
+	_ = paymentRecordUC.paymentRecordRepo.ReleasePollingLease(h.ctx, id, paymentRecordUC.lease.InstanceID)
+	h.cancel()
+	// Tidak ada cek berikutnya: waiter yang mendaftar selama cek terakhir menerima hasil final
+	paymentRecordUC.notifyWaiters(h, h.closeWaiters(), 0, true)
+}
+
+// stopTask menghentikan task (lokal maupun milik replica lain) dan menghapus state-nya dari Redis.
+// Replica pemilik diberi tahu lewat polling_control; jika pesan itu hilang, persist berikutnya
+// mendapati task sudah tidak terdaftar dan pemilik berhenti sendiri.
+func (paymentRecordUC *paymentRecordUseCase) stopTask(ctx context.Context, id uuid.UUID) {
+	paymentRecordUC.dropTask(id.String())
+	_ = paymentRecordUC.paymentRecordRepo.RemovePollingTask(ctx, id)
+	_ = paymentRecordUC.paymentRecordRepo.ReleasePollingLease(ctx, id, paymentRecordUC.lease.InstanceID)
+	paymentRecordUC.broadcastControl(ctx, entity.PollingControl{Action: entity.PollingControlCancel, ID: id})
+}
+
+// releaseTask melepas task yang sudah dihentikan replica lain: handle + lease instance ini.
+// State Redis tidak disentuh karena sudah dihapus oleh yang menghentikannya.
+func (paymentRecordUC *paymentRecordUseCase) releaseTask(h *taskHandle) {
+	id := h.task.ID
+	paymentRecordUC.tasks.CompareAndDelete(id.String(), h)
+	paymentRecordUC.scheduler.Remove(id.String())
+	_ = paymentRecordUC.paymentRecordRepo.ReleasePollingLease(context.WithoutCancel(h.ctx), id, paymentRecordUC.lease.InstanceID)
+	h.cancel()
+	for _, waiter := range h.closeWaiters() {
+		close(waiter)
 	}
 }
 
+// dropTask melepas task dari instance ini saja (lease hilang / diambil replica lain);
+// state di Redis tetap ada supaya pemilik baru bisa melanjutkan.
+func (paymentRecordUC *paymentRecordUseCase) dropTask(key string) {
+	v, ok := paymentRecordUC.tasks.LoadAndDelete(key)
+	if !ok {
+		return
+	}
+	paymentRecordUC.scheduler.Remove(key)
+	h := v.(*taskHandle)
+	h.cancel()
+	// Waiter yang belum dapat hasil dilepas; BoostTask mencoba lagi lewat pemilik baru
+	for _, waiter := range h.closeWaiters() {
+		close(waiter)
+	}
+}
+
+func (paymentRecordUC *paymentRecordUseCase) UpdateStatus(ctx context.Context, id uuid.UUID, status entity.PaymentStatus) error {
+	paymentRecordUC.logger.Info().Str("usecase", "UpdateStatus").Msgf("⚙️ Update payment record status to %s", status)
+	return paymentRecordUC.updateStatus(ctx, id, status)
+}
+
+// statusUpdateStopsTask true jika update status gagal permanen: record sudah final dengan status lain
+// atau sudah dihapus. Mencoba lagi tidak akan berhasil, jadi task dihentikan.
+func statusUpdateStopsTask(err error) bool {
+	return errors.Is(err, entity.ErrInvalidStatusTransition) || errors.Is(err, sql.ErrNoRows)
+}
+
+func (paymentRecordUC *paymentRecordUseCase) updateStatus(ctx context.Context, id uuid.UUID, status entity.PaymentStatus) error {
+	tx, err := paymentRecordUC.db.BeginTx(ctx, nil)
+	if err != nil {
+		paymentRecordUC.logger.Error().Err(err).Msg("❌ Failed to begin transaction")
+		return err
+	}
+
+	// Kunci row dulu supaya validasi transisi & update atomik
+	current, err := paymentRecordUC.paymentRecordRepo.FetchStatusForUpdate(ctx, tx, id)
+	if err != nil {
+		tx.Rollback()
+		paymentRecordUC.logger.Error().Err(err).Msg("❌ Failed to fetch payment record status, rolling back")
+		return err
+	}
+
+	if err := current.ValidateTransition(status); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := paymentRecordUC.paymentRecordRepo.UpdateStatus(ctx, tx, id, status); err != nil {
+		tx.Rollback()
+		paymentRecordUC.logger.Error().Err(err).Msg("❌ Failed to update payment record status, rolling back")
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		paymentRecordUC.logger.Error().Err(err).Msg("❌ Failed to commit transaction")
+		return err
+	}
+	return nil
+}
+
 func (paymentRecordUC *paymentRecordUseCase) BoostOtherTasksYangLama(id uuid.UUID) error {
 	paymentRecordUC.tasks.Range(func(key, _ interface{}) bool {
 		paymentID := key.(uuid.UUID)
 		if paymentID != id {
-			go paymentRecordUC.StartPolling(context.Background(), paymentID)
+			go paymentRecordUC.StartPolling(context.Background(), paymentID, "", "", nil)
 		}
 		return true
 	})
@@ -228,7 +929,8 @@ func (paymentRecordUC *paymentRecordUseCase) BoostOtherTasks(successID uuid.UUID
 		// kirim sinyal non-blocking; jika sudah ada sinyal pending, skip
 		select {
 		case h.wake <- struct{}{}:
-			paymentRecordUC.logger.Info().Msgf("🚀 Boosted task %s (reset delay to 10s & immediate check)", key)
+			paymentRecordUC.scheduler.Wake(key)
+			paymentRecordUC.logger.Info().Msgf("🚀 Boosted task %s (reset delay to %s & immediate check)", key, h.task.Policy.BoostResetDelay)
 		default:
 		}
 		return true
@@ -236,8 +938,179 @@ func (paymentRecordUC *paymentRecordUseCase) BoostOtherTasks(successID uuid.UUID
 	return nil
 }
 
+// BoostTask memicu cek segera untuk satu payment saja (reset delay ke BoostResetDelay)
+// dan menunggu hasil cek tersebut. Jika belum ada task, polling dimulai lebih dulu;
+// task yang dijalankan replica lain di-boost lewat replica pemiliknya.
+func (paymentRecordUC *paymentRecordUseCase) BoostTask(ctx context.Context, id uuid.UUID) (*entity.PollingTaskInfo, error) {
+	paymentRecordUC.logger.Info().Str("usecase", "BoostTask").Msgf("⚙️ Boost polling task %s", id)
+
+	// Task bisa dilepas / pindah pemilik di tengah boost; ulangi dari awal sekali lagi
+	for attempt := 0; attempt < boostAttempts; attempt++ {
+		info, err := paymentRecordUC.boostOnce(ctx, id)
+		if !errors.Is(err, errTaskReleased) {
+			return info, err
+		}
+	}
+	return nil, ErrPollingTaskNotOwned
+}
+
+func (paymentRecordUC *paymentRecordUseCase) boostOnce(ctx context.Context, id uuid.UUID) (*entity.PollingTaskInfo, error) {
+	key := id.String()
+	if _, ok := paymentRecordUC.tasks.Load(key); !ok {
+		err := paymentRecordUC.ensureTask(ctx, id)
+		if errors.Is(err, ErrPollingTaskNotOwned) {
+			return paymentRecordUC.forwardBoost(ctx, id)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	v, ok := paymentRecordUC.tasks.Load(key)
+	if !ok {
+		// Lease keburu diambil replica lain
+		return paymentRecordUC.forwardBoost(ctx, id)
+	}
+	return paymentRecordUC.boostLocal(ctx, v.(*taskHandle))
+}
+
+// boostLocal mem-boost task yang berjalan di instance ini dan menunggu hasil cek boost tersebut.
+func (paymentRecordUC *paymentRecordUseCase) boostLocal(ctx context.Context, h *taskHandle) (*entity.PollingTaskInfo, error) {
+	key := h.task.ID.String()
+	waiter := make(chan entity.PollingTaskInfo, 1)
+	if !h.addWaiter(waiter) {
+		return nil, errTaskReleased
+	}
+
+	select {
+	case h.wake <- struct{}{}:
+	default:
+	}
+	paymentRecordUC.scheduler.Wake(key)
+	paymentRecordUC.logger.Info().Msgf("🚀 Boosted task %s (reset delay to %s & immediate check)", key, h.task.Policy.BoostResetDelay)
+
+	select {
+	case info, ok := <-waiter:
+		if !ok {
+			return nil, errTaskReleased
+		}
+		return &info, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// forwardBoost meneruskan boost lewat pub/sub ke replica yang menjalankan task,
+// lalu menunggu balasan berisi hasil cek boost dari replica tersebut.
+func (paymentRecordUC *paymentRecordUseCase) forwardBoost(ctx context.Context, id uuid.UUID) (*entity.PollingTaskInfo, error) {
+	requestID := uuid.NewString()
+	reply := make(chan entity.PollingControl, 1)
+	paymentRecordUC.boostRequests.Store(requestID, reply)
+	defer paymentRecordUC.boostRequests.Delete(requestID)
+
+	msg := entity.PollingControl{
+		Action:    entity.PollingControlBoost,
+		ID:        id,
+		Origin:    paymentRecordUC.lease.InstanceID,
+		RequestID: requestID,
+	}
+	if err := paymentRecordUC.paymentRecordRepo.PublishPollingControl(ctx, msg); err != nil {
+		paymentRecordUC.logger.Error().Err(err).Msgf("❌ Failed to forward boost for %s", id)
+		return nil, err
+	}
+	paymentRecordUC.logger.Info().Msgf("📨 Boost for %s forwarded to owning instance", id)
+
+	select {
+	case res := <-reply:
+		switch {
+		case res.Error == errTaskReleased.Error():
+			return nil, errTaskReleased
+		case res.Error == context.DeadlineExceeded.Error():
+			return nil, context.DeadlineExceeded
+		case res.Error != "" || res.Task == nil:
+			return nil, fmt.Errorf("boost on %s failed: %s", res.Origin, res.Error)
+		}
+		return res.Task, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// handleBoost menjalankan boost yang diteruskan replica lain jika task-nya berjalan di sini,
+// lalu membalas hasilnya ke pengirim.
+func (paymentRecordUC *paymentRecordUseCase) handleBoost(ctx context.Context, msg entity.PollingControl) {
+	v, ok := paymentRecordUC.tasks.Load(msg.ID.String())
+	if !ok {
+		return // hanya replica yang menjalankan task yang menjawab
+	}
+
+	paymentRecordUC.wg.Add(1)
+	go func() {
+		defer paymentRecordUC.wg.Done()
+		boostCtx, cancel := context.WithTimeout(ctx, boostForwardTimeout)
+		defer cancel()
+
+		res := entity.PollingControl{
+			Action:    entity.PollingControlBoostResult,
+			ID:        msg.ID,
+			Origin:    paymentRecordUC.lease.InstanceID,
+			RequestID: msg.RequestID,
+		}
+		if info, err := paymentRecordUC.boostLocal(boostCtx, v.(*taskHandle)); err != nil {
+			res.Error = err.Error()
+		} else {
+			res.Task = info
+		}
+		if err := paymentRecordUC.paymentRecordRepo.PublishPollingControl(ctx, res); err != nil {
+			paymentRecordUC.logger.Warn().Err(err).Msgf("‼️ Failed to reply forwarded boost for %s", msg.ID)
+		}
+	}()
+}
+
+// ensureTask memulai task di instance ini: dari state Redis jika task yatim,
+// atau dari payment record jika belum pernah di-poll.
+func (paymentRecordUC *paymentRecordUseCase) ensureTask(ctx context.Context, id uuid.UUID) error {
+	task, err := paymentRecordUC.paymentRecordRepo.FetchPollingTask(ctx, id)
+	if err == nil {
+		owner, err := paymentRecordUC.paymentRecordRepo.GetPollingLeaseOwner(ctx, id)
+		if err != nil {
+			return err
+		}
+		if owner != "" && owner != paymentRecordUC.lease.InstanceID {
+			return ErrPollingTaskNotOwned
+		}
+		if task.Policy.Validate() != nil {
+			task.Policy = paymentRecordUC.pollingPolicies.Resolve(task.Tag, nil)
+		}
+		// Task lama tanpa provider → routing lewat tag
+		if task.Provider == "" {
+			task.Provider, _ = paymentRecordUC.paymentProviders.Resolve("", task.Tag)
+		}
+		// Task hidup lebih lama dari request → jangan pakai ctx request
+		return paymentRecordUC.startTask(context.Background(), *task, true)
+	}
+	if !errors.Is(err, ErrPollingTaskNotFound) {
+		return err
+	}
+
+	paymentRecord, err := paymentRecordUC.paymentRecordRepo.FetchByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if paymentRecord.Status.IsFinal() {
+		return ErrPaymentFinalized
+	}
+	if paymentRecord.Status == entity.PaymentStatusTimedOut {
+		if err := paymentRecordUC.updateStatus(ctx, id, entity.PaymentStatusPending); err != nil {
+			return err
+		}
+	}
+	return paymentRecordUC.StartPolling(context.Background(), id, paymentRecord.Tag, paymentRecord.Provider, nil)
+}
+
 func (u *paymentRecordUseCase) StartConsumer(ctx context.Context) error {
+	u.wg.Add(1)
 	go func() {
+		defer u.wg.Done()
 		backoff := 500 * time.Millisecond
 		maxBackoff := 5 * time.Second
 
@@ -301,6 +1174,12 @@ func (u *paymentRecordUseCase) StartConsumer(ctx context.Context) error {
 
 func (paymentRecordUC *paymentRecordUseCase) Create(ctx context.Context, paymentRecord entity.PaymentRecord) (*entity.PaymentRecord, error) {
 	paymentRecordUC.logger.Info().Str("usecase", "Create").Msg("⚙️ Store payment records")
+	provider, err := paymentRecordUC.paymentProviders.Resolve(paymentRecord.Provider, paymentRecord.Tag)
+	if err != nil {
+		return nil, err
+	}
+	paymentRecord.Provider = provider
+
 	tx, err := paymentRecordUC.db.Begin()
 	if err != nil {
 		paymentRecordUC.logger.Error().Err(err).Msg("❌ Failed to begin transaction")
@@ -356,16 +1235,330 @@ func (paymentRecordUC *paymentRecordUseCase) ListRunningTasks() []uuid.UUID {
 	return ids
 }
 
+// ListTasks mengembalikan task dari semua replica (state Redis), difilter & dipaginasi,
+// diurutkan dari yang paling lama berjalan.
+func (paymentRecordUC *paymentRecordUseCase) ListTasks(ctx context.Context, filter entity.PollingTaskFilter) ([]entity.PollingTaskInfo, int, error) {
+	paymentRecordUC.logger.Info().Str("usecase", "ListTasks").Msg("⚙️ Fetching polling tasks")
+	tasks, total, err := paymentRecordUC.paymentRecordRepo.ListPollingTasks(ctx, filter, time.Now())
+	if err != nil {
+		return nil, 0, err
+	}
+	return paymentRecordUC.describeTasks(ctx, tasks), total, nil
+}
+
+// ListCheckHistories mengembalikan riwayat percobaan cek satu payment dari payment_record_check_logs.
+// nextCursor kosong kalau sudah di halaman terakhir; client bisa mulai dari page 1 lalu lanjut pakai cursor.
+func (paymentRecordUC *paymentRecordUseCase) ListCheckHistories(ctx context.Context, filter entity.CheckHistoryFilter) ([]entity.PaymentRecordCheckAttempt, int, string, error) {
+	paymentRecordUC.logger.Info().Str("usecase", "ListCheckHistories").Msgf("⚙️ Fetching check histories %s", filter.PaymentID)
+	logs, total, err := paymentRecordUC.paymentRecordCheckLogRepo.FetchByPaymentID(ctx, filter)
+	if err != nil {
+		return nil, 0, "", err
+	}
+
+	hasMore := (filter.Page-1)*filter.PerPage+len(logs) < total
+	if filter.Cursor != nil {
+		hasMore = len(logs) > filter.PerPage
+		if hasMore {
+			logs = logs[:filter.PerPage]
+		}
+	}
+
+	var nextCursor string
+	if hasMore && len(logs) > 0 {
+		if last := logs[len(logs)-1]; last.OccurredAt != nil {
+			nextCursor = entity.CheckHistoryCursor{OccurredAt: *last.OccurredAt, ID: last.ID}.Encode()
+		}
+	}
+
+	attempts := make([]entity.PaymentRecordCheckAttempt, 0, len(logs))
+	for _, log := range logs {
+		attempts = append(attempts, entity.NewPaymentRecordCheckAttempt(log))
+	}
+	return attempts, total, nextCursor, nil
+}
+
+func (paymentRecordUC *paymentRecordUseCase) GetTask(ctx context.Context, id uuid.UUID) (*entity.PollingTaskInfo, error) {
+	paymentRecordUC.logger.Info().Str("usecase", "GetTask").Msgf("⚙️ Fetching polling task %s", id)
+	task, err := paymentRecordUC.paymentRecordRepo.FetchPollingTask(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	info := paymentRecordUC.describeTasks(ctx, []entity.PollingTask{*task})[0]
+	return &info, nil
+}
+
+// describeTasks melengkapi state task dengan jadwal retry, pemilik lease, dan status pause;
+// dibaca sekali per halaman (MGET jadwal, MGET lease, satu pipeline pause), bukan per task.
+func (paymentRecordUC *paymentRecordUseCase) describeTasks(ctx context.Context, tasks []entity.PollingTask) []entity.PollingTaskInfo {
+	ids := make([]uuid.UUID, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.ID
+	}
+	nextRetries, err := paymentRecordUC.paymentRecordRepo.GetNextRetries(ctx, ids)
+	if err != nil {
+		paymentRecordUC.logger.Warn().Err(err).Msg("‼️ Failed to read next retry of polling tasks")
+	}
+	owners, err := paymentRecordUC.paymentRecordRepo.FetchPollingLeaseOwners(ctx, ids)
+	if err != nil {
+		paymentRecordUC.logger.Warn().Err(err).Msg("‼️ Failed to read polling lease owners")
+	}
+	pauseState, err := paymentRecordUC.paymentRecordRepo.FetchPauseStates(ctx)
+	if err != nil {
+		paymentRecordUC.logger.Warn().Err(err).Msg("‼️ Failed to read pause state")
+	}
+	now := time.Now()
+
+	infos := make([]entity.PollingTaskInfo, 0, len(tasks))
+	for _, task := range tasks {
+		info := entity.PollingTaskInfo{
+			PollingTask: task,
+			Owner:       owners[task.ID],
+			Paused:      pauseState.Paused(task.ID, task.Provider, now),
+		}
+		if nextRetry, ok := nextRetries[task.ID]; ok {
+			info.NextRetryAt = &nextRetry
+		}
+		infos = append(infos, info)
+	}
+	return infos
+}
+
 func (paymentRecordUC *paymentRecordUseCase) RestorePollingTasks(ctx context.Context) error {
-	ids, err := paymentRecordUC.paymentRecordRepo.RestorePollingTasks(ctx)
+	tasks, err := paymentRecordUC.paymentRecordRepo.RestorePollingTasks(ctx)
 	if err != nil {
 		paymentRecordUC.logger.Error().Err(err).Msg("❌ Failed to restore polling tasks from Redis")
 		return err
 	}
+	// Task dari versi sebelum index list ada belum masuk index started_at
+	if err := paymentRecordUC.paymentRecordRepo.IndexPollingTasks(ctx, tasks); err != nil {
+		paymentRecordUC.logger.Warn().Err(err).Msg("‼️ Failed to index polling tasks")
+	}
+	return paymentRecordUC.takeOverOrphans(ctx, tasks)
+}
+
+// takeOverOrphans memulai task yang tidak berjalan di instance ini dan lease-nya hilang (pemilik mati)
+// atau masih atas nama instance ini (restart dengan INSTANCE_ID sama). Pemilik lease dibaca sekaligus,
+// jadi task milik replica lain yang masih hidup tidak disentuh sama sekali.
+func (paymentRecordUC *paymentRecordUseCase) takeOverOrphans(ctx context.Context, tasks []entity.PollingTask) error {
+	var candidates []entity.PollingTask
+	var ids []uuid.UUID
+	for _, task := range tasks {
+		if _, running := paymentRecordUC.tasks.Load(task.ID.String()); running {
+			continue
+		}
+		candidates = append(candidates, task)
+		ids = append(ids, task.ID)
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	owners, err := paymentRecordUC.paymentRecordRepo.FetchPollingLeaseOwners(ctx, ids)
+	if err != nil {
+		paymentRecordUC.logger.Warn().Err(err).Msg("‼️ Failed to read polling lease owners")
+		return err
+	}
+	var orphans []entity.PollingTask
+	for _, task := range candidates {
+		if owner := owners[task.ID]; owner == "" || owner == paymentRecordUC.lease.InstanceID {
+			orphans = append(orphans, task)
+		}
+	}
+	paymentRecordUC.restoreTasks(ctx, orphans)
+	return nil
+}
+
+func (paymentRecordUC *paymentRecordUseCase) restoreTasks(ctx context.Context, tasks []entity.PollingTask) {
+	for _, task := range tasks {
+		if _, running := paymentRecordUC.tasks.Load(task.ID.String()); running {
+			continue
+		}
+		paymentRecordUC.logger.Debug().Msgf("♻️ Restoring polling task: %s", task.ID)
+		// Task tanpa policy tersimpan → pakai policy default untuk tag-nya
+		if task.Policy.Validate() != nil {
+			task.Policy = paymentRecordUC.pollingPolicies.Resolve(task.Tag, nil)
+		}
+		// Task lama tanpa provider → routing lewat tag
+		if task.Provider == "" {
+			task.Provider, _ = paymentRecordUC.paymentProviders.Resolve("", task.Tag)
+		}
+		_ = paymentRecordUC.startTask(ctx, task, true)
+	}
+}
+
+// StartLeaseKeeper memperpanjang lease task milik instance ini secara berkala,
+// melepas task yang lease-nya hilang atau sudah dihapus dari Redis (mis. dibatalkan lewat replica lain),
+// dan mengambil alih task yang pemiliknya sudah mati (lease-nya expired).
+// Per heartbeat: satu pipeline renew, satu SMEMBERS+HGETALL, dan MGET pemilik lease untuk task yang tidak berjalan di sini.
+func (paymentRecordUC *paymentRecordUseCase) StartLeaseKeeper(ctx context.Context) error {
+	paymentRecordUC.wg.Add(1)
+	go func() {
+		defer paymentRecordUC.wg.Done()
+		ticker := time.NewTicker(paymentRecordUC.lease.Heartbeat)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				paymentRecordUC.logger.Info().Msg("⁉️ Lease keeper stopped")
+				return
+			case <-ticker.C:
+			}
+
+			ids := paymentRecordUC.ListRunningTasks()
+			lost, err := paymentRecordUC.paymentRecordRepo.RenewPollingLeases(ctx, ids, paymentRecordUC.lease.InstanceID, paymentRecordUC.lease.TTL)
+			if err != nil {
+				paymentRecordUC.logger.Warn().Err(err).Msg("‼️ Failed to renew polling leases")
+				continue
+			}
+			for _, id := range lost {
+				paymentRecordUC.logger.Warn().Msgf("‼️ Lost polling lease, dropping task: %s", id)
+				paymentRecordUC.dropTask(id.String())
+			}
+
+			readAt := time.Now()
+			persisted, err := paymentRecordUC.paymentRecordRepo.RestorePollingTasks(ctx)
+			if err != nil {
+				paymentRecordUC.logger.Warn().Err(err).Msg("‼️ Failed to read polling tasks from Redis")
+				continue
+			}
+
+			// Task lokal yang sudah tidak ada di Redis → dibatalkan dari replica lain.
+			// Task yang state-nya baru tersimpan setelah Redis dibaca (startTask yang sedang berjalan) dilewati.
+			known := make(map[uuid.UUID]bool, len(persisted))
+			for _, task := range persisted {
+				known[task.ID] = true
+			}
+			for _, id := range ids {
+				if known[id] {
+					continue
+				}
+				v, ok := paymentRecordUC.tasks.Load(id.String())
+				if !ok || !v.(*taskHandle).persistedBefore(readAt) {
+					continue
+				}
+				paymentRecordUC.logger.Info().Msgf("🛑 Task removed from Redis, dropping: %s", id)
+				paymentRecordUC.dropTask(id.String())
+			}
+
+			// Ambil alih task yatim (lease expired)
+			_ = paymentRecordUC.takeOverOrphans(ctx, persisted)
+
+			// Sinkron ulang cache pause jika ada pesan kontrol yang terlewat
+			_ = paymentRecordUC.refreshPauseState(ctx)
+		}
+	}()
+	return nil
+}
+
+func (paymentRecordUC *paymentRecordUseCase) CancelTask(ctx context.Context, id uuid.UUID) error {
+	paymentRecordUC.logger.Info().Str("usecase", "CancelTask").Msgf("⚙️ Cancel polling task %s", id)
+	if _, err := paymentRecordUC.paymentRecordRepo.FetchPollingTask(ctx, id); err != nil {
+		return err
+	}
+	return paymentRecordUC.cancelTask(ctx, id)
+}
+
+func (paymentRecordUC *paymentRecordUseCase) CancelTasksByTag(ctx context.Context, tag string) ([]uuid.UUID, error) {
+	paymentRecordUC.logger.Info().Str("usecase", "CancelTasksByTag").Msgf("⚙️ Cancel polling tasks with tag %q", tag)
+	tasks, err := paymentRecordUC.paymentRecordRepo.RestorePollingTasks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var cancelled []uuid.UUID
+	for _, task := range tasks {
+		if task.Tag != tag {
+			continue
+		}
+		if err := paymentRecordUC.cancelTask(ctx, task.ID); err != nil {
+			paymentRecordUC.logger.Warn().Err(err).Msgf("‼️ Failed to cancel polling task %s", task.ID)
+			continue
+		}
+		cancelled = append(cancelled, task.ID)
+	}
+	return cancelled, nil
+}
+
+func (paymentRecordUC *paymentRecordUseCase) PauseTask(ctx context.Context, id uuid.UUID) error {
+	paymentRecordUC.logger.Info().Str("usecase", "PauseTask").Msgf("⚙️ Pause polling task %s", id)
+	if _, err := paymentRecordUC.paymentRecordRepo.FetchPollingTask(ctx, id); err != nil {
+		return err
+	}
+	if err := paymentRecordUC.paymentRecordRepo.SetPollingTaskPaused(ctx, id, true); err != nil {
+		return err
+	}
+	paymentRecordUC.pause.setTask(id, true)
+	paymentRecordUC.broadcastControl(ctx, entity.PollingControl{Action: entity.PollingControlPause, ID: id})
+	return nil
+}
+
+// ResumeTask melanjutkan task dan langsung memicu cek di replica pemiliknya.
+func (paymentRecordUC *paymentRecordUseCase) ResumeTask(ctx context.Context, id uuid.UUID) error {
+	paymentRecordUC.logger.Info().Str("usecase", "ResumeTask").Msgf("⚙️ Resume polling task %s", id)
+	if _, err := paymentRecordUC.paymentRecordRepo.FetchPollingTask(ctx, id); err != nil {
+		return err
+	}
+	if err := paymentRecordUC.paymentRecordRepo.SetPollingTaskPaused(ctx, id, false); err != nil {
+		return err
+	}
+	paymentRecordUC.pause.setTask(id, false)
+	paymentRecordUC.scheduler.Wake(id.String())
+	paymentRecordUC.broadcastControl(ctx, entity.PollingControl{Action: entity.PollingControlResume, ID: id})
+	return nil
+}
+
+func (paymentRecordUC *paymentRecordUseCase) PauseAll(ctx context.Context) error {
+	paymentRecordUC.logger.Info().Str("usecase", "PauseAll").Msg("⚙️ Pause all polling tasks")
+	if err := paymentRecordUC.paymentRecordRepo.SetPollingPaused(ctx, true); err != nil {
+		return err
+	}
+	paymentRecordUC.pause.setAll(true)
+	paymentRecordUC.broadcastControl(ctx, entity.PollingControl{Action: entity.PollingControlPauseAll})
+	return nil
+}
+
+func (paymentRecordUC *paymentRecordUseCase) ResumeAll(ctx context.Context) error {
+	paymentRecordUC.logger.Info().Str("usecase", "ResumeAll").Msg("⚙️ Resume all polling tasks")
+	if err := paymentRecordUC.paymentRecordRepo.SetPollingPaused(ctx, false); err != nil {
+		return err
+	}
+	paymentRecordUC.pause.setAll(false)
+	paymentRecordUC.wakeAll()
+	paymentRecordUC.broadcastControl(ctx, entity.PollingControl{Action: entity.PollingControlResumeAll})
+	return nil
+}
+
+func (paymentRecordUC *paymentRecordUseCase) cancelTask(ctx context.Context, id uuid.UUID) error {
+	// Tandai CANCELLED dulu; record yang sudah final (mis. PAID) tidak boleh dibatalkan
+	if err := paymentRecordUC.updateStatus(ctx, id, entity.PaymentStatusCancelled); err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	paymentRecordUC.stopTask(ctx, id)
+	paymentRecordUC.logger.Info().Msgf("🛑 Polling task cancelled: %s", id)
+	return nil
+}
+
+// Shutdown menunggu worker scheduler (cek yang sedang jalan + tulis check log-nya),
+// lease keeper, dan Kafka consumer selesai, lalu melepas lease supaya replica lain
+// bisa langsung mengambil alih. Context root harus sudah dibatalkan sebelumnya.
+func (paymentRecordUC *paymentRecordUseCase) Shutdown(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		paymentRecordUC.scheduler.Stop()
+		paymentRecordUC.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		paymentRecordUC.logger.Info().Msg("🔒 Polling workers and Kafka consumer stopped.")
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 
-	for _, id := range ids {
-		paymentRecordUC.logger.Info().Msgf("♻️ Restoring polling task: %s", id)
-		_ = paymentRecordUC.StartPolling(ctx, id)
+	for _, id := range paymentRecordUC.ListRunningTasks() {
+		_ = paymentRecordUC.paymentRecordRepo.ReleasePollingLease(ctx, id, paymentRecordUC.lease.InstanceID)
 	}
 	return nil
 }
@@ -0,0 +1,395 @@
+package usecase
+
+import (
+	"beta-payment-api-client/internal/entity"
+	"beta-payment-api-client/internal/pkg/payment_provider"
+	pkgPaymentServer "beta-payment-api-client/internal/pkg/payment_server"
+	"beta-payment-api-client/internal/pkg/scheduler"
+	"beta-payment-api-client/internal/repository"
+	"beta-payment-api-client/internal/valueobject"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+const waitTimeout = 2 * time.Second
+
+// fakeCluster state Redis + Postgres bersama yang dipakai beberapa replica dalam satu test.
+type fakeCluster struct {
+	mu        sync.Mutex
+	tasks     map[uuid.UUID]entity.PollingTask
+	leases    map[uuid.UUID]string
+	statuses  map[uuid.UUID]entity.PaymentStatus
+	providers map[string]time.Time // provider yang di-pause → akhir cooldown
+	subs      []chan entity.PollingControl
+}
+
+func newFakeCluster() *fakeCluster {
+	return &fakeCluster{
+		tasks:     map[uuid.UUID]entity.PollingTask{},
+		leases:    map[uuid.UUID]string{},
+		statuses:  map[uuid.UUID]entity.PaymentStatus{},
+		providers: map[string]time.Time{},
+	}
+}
+
+func (c *fakeCluster) hasTask(id uuid.UUID) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ok := c.tasks[id]
+	return ok
+}
+
+func (c *fakeCluster) leaseOwner(id uuid.UUID) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.leases[id]
+}
+
+func (c *fakeCluster) status(id uuid.UUID) entity.PaymentStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.statuses[id]
+}
+
+// fakeRepo hanya mengimplementasikan method yang dipakai alur start / cancel / cek;
+// method lain panic lewat interface yang di-embed.
+type fakeRepo struct {
+	repository.PaymentRecordRepository
+	cluster *fakeCluster
+}
+
+func (r *fakeRepo) AcquirePollingLease(_ context.Context, id uuid.UUID, owner string, _ time.Duration) (bool, error) {
+	r.cluster.mu.Lock()
+	defer r.cluster.mu.Unlock()
+	if current, ok := r.cluster.leases[id]; ok && current != owner {
+		return false, nil
+	}
+	r.cluster.leases[id] = owner
+	return true, nil
+}
+
+func (r *fakeRepo) ReleasePollingLease(_ context.Context, id uuid.UUID, owner string) error {
+	r.cluster.mu.Lock()
+	defer r.cluster.mu.Unlock()
+	if r.cluster.leases[id] == owner {
+		delete(r.cluster.leases, id)
+	}
+	return nil
+}
+
+func (r *fakeRepo) PersistPollingTask(_ context.Context, task entity.PollingTask) error {
+	r.cluster.mu.Lock()
+	defer r.cluster.mu.Unlock()
+	r.cluster.tasks[task.ID] = task
+	return nil
+}
+
+func (r *fakeRepo) UpdatePollingTask(_ context.Context, task entity.PollingTask) (bool, error) {
+	r.cluster.mu.Lock()
+	defer r.cluster.mu.Unlock()
+	if _, ok := r.cluster.tasks[task.ID]; !ok {
+		return false, nil
+	}
+	r.cluster.tasks[task.ID] = task
+	return true, nil
+}
+
+func (r *fakeRepo) RemovePollingTask(_ context.Context, id uuid.UUID) error {
+	r.cluster.mu.Lock()
+	defer r.cluster.mu.Unlock()
+	delete(r.cluster.tasks, id)
+	return nil
+}
+
+func (r *fakeRepo) FetchPollingTask(_ context.Context, id uuid.UUID) (*entity.PollingTask, error) {
+	r.cluster.mu.Lock()
+	defer r.cluster.mu.Unlock()
+	task, ok := r.cluster.tasks[id]
+	if !ok {
+		return nil, repository.ErrPollingTaskNotFound
+	}
+	return &task, nil
+}
+
+func (r *fakeRepo) SetNextRetry(context.Context, uuid.UUID, time.Duration) error {
+	return nil
+}
+
+func (r *fakeRepo) SetPollingProviderPaused(_ context.Context, provider string, until time.Time) error {
+	r.cluster.mu.Lock()
+	defer r.cluster.mu.Unlock()
+	r.cluster.providers[provider] = until
+	return nil
+}
+
+func (r *fakeRepo) FetchPauseStates(context.Context) (entity.PollingPauseState, error) {
+	return entity.PollingPauseState{}, nil
+}
+
+func (r *fakeRepo) SubscribePollingControl(ctx context.Context) (<-chan entity.PollingControl, error) {
+	ch := make(chan entity.PollingControl, 16)
+	r.cluster.mu.Lock()
+	r.cluster.subs = append(r.cluster.subs, ch)
+	r.cluster.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		r.cluster.mu.Lock()
+		defer r.cluster.mu.Unlock()
+		for i, sub := range r.cluster.subs {
+			if sub == ch {
+				r.cluster.subs = append(r.cluster.subs[:i], r.cluster.subs[i+1:]...)
+				break
+			}
+		}
+		close(ch)
+	}()
+	return ch, nil
+}
+
+func (r *fakeRepo) PublishPollingControl(_ context.Context, msg entity.PollingControl) error {
+	r.cluster.mu.Lock()
+	defer r.cluster.mu.Unlock()
+	for _, sub := range r.cluster.subs {
+		sub <- msg
+	}
+	return nil
+}
+
+func (r *fakeRepo) FetchStatusForUpdate(_ context.Context, _ *sql.Tx, id uuid.UUID) (entity.PaymentStatus, error) {
+	r.cluster.mu.Lock()
+	defer r.cluster.mu.Unlock()
+	status, ok := r.cluster.statuses[id]
+	if !ok {
+		return "", sql.ErrNoRows
+	}
+	return status, nil
+}
+
+func (r *fakeRepo) UpdateStatus(_ context.Context, _ *sql.Tx, id uuid.UUID, status entity.PaymentStatus) error {
+	r.cluster.mu.Lock()
+	defer r.cluster.mu.Unlock()
+	r.cluster.statuses[id] = status
+	return nil
+}
+
+type fakeCheckLogRepo struct {
+	repository.PaymentRecordCheckLogRepository
+}
+
+func (fakeCheckLogRepo) LogFetchAttempt(*entity.PaymentRecordCheckHTTP, time.Duration) error {
+	return nil
+}
+
+// fakeProvider selalu menjawab hal yang sama: status (200) atau err dengan status code-nya.
+type fakeProvider struct {
+	status string
+	code   int
+	err    error
+}
+
+func (p fakeProvider) Name() string { return "default" }
+
+func (p fakeProvider) FetchStatus(_ context.Context, id uuid.UUID) (string, *entity.PaymentRecordCheckHTTP, error) {
+	if p.err != nil {
+		return "", &entity.PaymentRecordCheckHTTP{ID: id, StatusCode: p.code}, p.err
+	}
+	return p.status, &entity.PaymentRecordCheckHTTP{ID: id, StatusCode: http.StatusOK}, nil
+}
+
+func (p fakeProvider) Replay(context.Context, string, string, http.Header, []byte) (*entity.PaymentRecordCheckHTTP, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (p fakeProvider) Health(context.Context) error { return nil }
+
+// fakeConnector database/sql tanpa query: updateStatus hanya butuh Begin/Commit/Rollback,
+// baca / tulis status lewat fakeRepo.
+type fakeConnector struct{}
+
+func (fakeConnector) Connect(context.Context) (driver.Conn, error) { return fakeConn{}, nil }
+func (fakeConnector) Driver() driver.Driver                        { return fakeDriver{} }
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) { return fakeConn{}, nil }
+
+type fakeConn struct{}
+
+func (fakeConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not implemented") }
+func (fakeConn) Close() error                        { return nil }
+func (fakeConn) Begin() (driver.Tx, error)           { return fakeTx{}, nil }
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return nil }
+
+// newTestReplica membuat satu replica yang berbagi cluster; scheduler tidak dijalankan,
+// cek dipicu langsung lewat runTask supaya urutan kejadian deterministik.
+func newTestReplica(t *testing.T, cluster *fakeCluster, instanceID string, provider payment_provider.PaymentProvider) *paymentRecordUseCase {
+	t.Helper()
+	db := sql.OpenDB(fakeConnector{})
+	t.Cleanup(func() { db.Close() })
+
+	second := valueobject.Duration{Duration: time.Second}
+	return NewPaymentRecordUseCase(
+		&fakeRepo{cluster: cluster},
+		fakeCheckLogRepo{},
+		payment_provider.NewRegistry(provider),
+		entity.StatusMappings{Default: entity.DefaultStatusMapping()},
+		entity.PollingPolicySet{Default: entity.PollingPolicy{
+			InitialDelay:    second,
+			Multiplier:      2,
+			MaxDelay:        valueobject.Duration{Duration: time.Minute},
+			BoostResetDelay: second,
+		}},
+		scheduler.NewScheduler(1, 0, zerolog.Nop()),
+		PollingLeaseConfig{InstanceID: instanceID, TTL: time.Minute, Heartbeat: time.Second},
+		db,
+		zerolog.Nop(),
+	).(*paymentRecordUseCase)
+}
+
+func startListener(t *testing.T, uc *paymentRecordUseCase) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := uc.StartControlListener(ctx); err != nil {
+		t.Fatalf("StartControlListener: %v", err)
+	}
+	t.Cleanup(func() {
+		cancel()
+		uc.wg.Wait()
+	})
+}
+
+func hasHandle(uc *paymentRecordUseCase, id uuid.UUID) bool {
+	_, ok := uc.tasks.Load(id.String())
+	return ok
+}
+
+func TestCancelTaskAcrossReplicas(t *testing.T) {
+	tests := []struct {
+		name string
+		// broadcast=false: pesan cancel hilang (owner tidak subscribe), owner harus
+		// berhenti sendiri saat persist berikutnya mendapati task sudah dihapus
+		broadcast bool
+	}{
+		{name: "owner drops task on cancel broadcast", broadcast: true},
+		{name: "owner stops on next check when broadcast is lost", broadcast: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster := newFakeCluster()
+			pending := fakeProvider{status: string(entity.PaymentStatusPending)}
+			owner := newTestReplica(t, cluster, "replica-a", pending)
+			other := newTestReplica(t, cluster, "replica-b", pending)
+			if tt.broadcast {
+				startListener(t, owner)
+			}
+			startListener(t, other)
+
+			id := uuid.New()
+			cluster.statuses[id] = entity.PaymentStatusPending
+			if err := owner.StartPolling(context.Background(), id, "", "", nil); err != nil {
+				t.Fatalf("StartPolling: %v", err)
+			}
+			if !hasHandle(owner, id) || cluster.leaseOwner(id) != "replica-a" {
+				t.Fatalf("task not started on owner replica")
+			}
+
+			// Cancel diterima replica yang bukan pemilik
+			if err := other.CancelTask(context.Background(), id); err != nil {
+				t.Fatalf("CancelTask: %v", err)
+			}
+			if got := cluster.status(id); got != entity.PaymentStatusCancelled {
+				t.Fatalf("status = %s, want %s", got, entity.PaymentStatusCancelled)
+			}
+
+			if tt.broadcast {
+				deadline := time.Now().Add(waitTimeout)
+				for hasHandle(owner, id) {
+					if time.Now().After(deadline) {
+						t.Fatalf("owner still holds the task after cancel broadcast")
+					}
+					time.Sleep(5 * time.Millisecond)
+				}
+			}
+
+			// Cek di owner (sudah terjadwal sebelum cancel) tidak boleh menghidupkan task lagi
+			if _, done := owner.runTask(context.Background(), id.String()); !done {
+				t.Fatalf("runTask after cancel: done = false, want true")
+			}
+			if hasHandle(owner, id) {
+				t.Errorf("owner still holds the task")
+			}
+			if cluster.hasTask(id) {
+				t.Errorf("cancelled task was persisted again")
+			}
+			if got := cluster.leaseOwner(id); got != "" {
+				t.Errorf("lease still held by %q", got)
+			}
+			if got := cluster.status(id); got != entity.PaymentStatusCancelled {
+				t.Errorf("status = %s, want %s", got, entity.PaymentStatusCancelled)
+			}
+		})
+	}
+}
+
+func TestCheckTaskAccessErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		code int
+		kind error
+		// wantProviderPaused: semua task provider ikut berhenti; selain itu hanya task ini yang mundur
+		wantProviderPaused bool
+		wantNext           time.Duration // 0 = tidak dicek
+	}{
+		{name: "401 pauses the provider", code: http.StatusUnauthorized, kind: pkgPaymentServer.ErrUnauthorized, wantProviderPaused: true},
+		{name: "403 backs off only this task", code: http.StatusForbidden, kind: pkgPaymentServer.ErrForbidden, wantNext: time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster := newFakeCluster()
+			provider := fakeProvider{code: tt.code, err: &pkgPaymentServer.ResponseError{Kind: tt.kind, StatusCode: tt.code}}
+			uc := newTestReplica(t, cluster, "replica-a", provider)
+
+			id := uuid.New()
+			cluster.statuses[id] = entity.PaymentStatusPending
+			if err := uc.StartPolling(context.Background(), id, "", "", nil); err != nil {
+				t.Fatalf("StartPolling: %v", err)
+			}
+
+			next, done := uc.runTask(context.Background(), id.String())
+			if done {
+				t.Fatalf("runTask: done = true, want false")
+			}
+			if tt.wantNext > 0 && next != tt.wantNext {
+				t.Errorf("next check in %s, want %s", next, tt.wantNext)
+			}
+			if paused := uc.pause.paused(uuid.New(), provider.Name()); paused != tt.wantProviderPaused {
+				t.Errorf("other tasks of provider paused = %v, want %v", paused, tt.wantProviderPaused)
+			}
+			cluster.mu.Lock()
+			_, pausedInRedis := cluster.providers[provider.Name()]
+			cluster.mu.Unlock()
+			if pausedInRedis != tt.wantProviderPaused {
+				t.Errorf("provider pause stored = %v, want %v", pausedInRedis, tt.wantProviderPaused)
+			}
+			if !cluster.hasTask(id) {
+				t.Errorf("task removed from Redis, want it kept for the next check")
+			}
+		})
+	}
+}
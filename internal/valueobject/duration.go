@@ -0,0 +1,48 @@
+package valueobject
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+type Duration struct {
+	time.Duration
+}
+
+//
+// 👇 JSON SUPPORT
+//
+
+// Encode as Go duration string, e.g. "10s", "1m30s"
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON accepts both duration string ("10s") and number of seconds (10)
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		d.Duration = 0
+		return nil
+	}
+
+	if data[0] == '"' {
+		var str string
+		if err := json.Unmarshal(data, &str); err != nil {
+			return err
+		}
+		parsed, err := time.ParseDuration(str)
+		if err != nil {
+			return fmt.Errorf("invalid duration value: %v", err)
+		}
+		d.Duration = parsed
+		return nil
+	}
+
+	var seconds float64
+	if err := json.Unmarshal(data, &seconds); err != nil {
+		return fmt.Errorf("invalid duration value: %v", err)
+	}
+	d.Duration = time.Duration(seconds * float64(time.Second))
+	return nil
+}
@@ -0,0 +1,3 @@
+ALTER TABLE payment_records DROP CONSTRAINT IF EXISTS chk_payment_records_status;
+
+ALTER TABLE payment_records ALTER COLUMN status DROP DEFAULT;
\ No newline at end of file
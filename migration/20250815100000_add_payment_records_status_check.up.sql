@@ -0,0 +1,12 @@
+-- Record lama dibuat dengan status kosong → anggap PENDING
+UPDATE payment_records SET status = 'PENDING' WHERE status = '';
+
+ALTER TABLE payment_records ALTER COLUMN status SET DEFAULT 'PENDING';
+
+DO $$
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'chk_payment_records_status') THEN
+ALTER TABLE payment_records ADD CONSTRAINT chk_payment_records_status
+    CHECK (status IN ('PENDING', 'PAID', 'UNPAID', 'FAILED', 'EXPIRED', 'CANCELLED', 'TIMED_OUT'));
+END IF;
+END$$;
\ No newline at end of file
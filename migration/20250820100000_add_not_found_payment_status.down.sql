@@ -0,0 +1,7 @@
+-- NOT_FOUND tidak dikenal constraint lama → anggap TIMED_OUT (polling berhenti tanpa status final)
+UPDATE payment_records SET status = 'TIMED_OUT' WHERE status = 'NOT_FOUND';
+
+ALTER TABLE payment_records DROP CONSTRAINT IF EXISTS chk_payment_records_status;
+
+ALTER TABLE payment_records ADD CONSTRAINT chk_payment_records_status
+    CHECK (status IN ('PENDING', 'PAID', 'UNPAID', 'FAILED', 'EXPIRED', 'CANCELLED', 'TIMED_OUT'));
\ No newline at end of file
@@ -0,0 +1,5 @@
+-- Payment yang tidak dikenal payment server (404) ditandai NOT_FOUND
+ALTER TABLE payment_records DROP CONSTRAINT IF EXISTS chk_payment_records_status;
+
+ALTER TABLE payment_records ADD CONSTRAINT chk_payment_records_status
+    CHECK (status IN ('PENDING', 'PAID', 'UNPAID', 'FAILED', 'EXPIRED', 'CANCELLED', 'TIMED_OUT', 'NOT_FOUND'));
\ No newline at end of file
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_payment_records_provider;
+
+ALTER TABLE payment_records DROP COLUMN IF EXISTS provider;
\ No newline at end of file
@@ -0,0 +1,9 @@
+-- Provider (PSP) yang mem-poll payment; kosong = record lama, routing lewat tag / provider default
+ALTER TABLE payment_records ADD COLUMN IF NOT EXISTS provider TEXT NOT NULL DEFAULT '';
+
+DO $$
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM pg_indexes WHERE indexname = 'idx_payment_records_provider') THEN
+CREATE INDEX idx_payment_records_provider ON payment_records(provider);
+END IF;
+END$$;
\ No newline at end of file
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_payment_record_check_logs_payment_id_occurred_at;
\ No newline at end of file
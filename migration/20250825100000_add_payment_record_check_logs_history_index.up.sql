@@ -0,0 +1,7 @@
+-- Index untuk riwayat cek per payment (filter rentang waktu + paginasi cursor pada (occurred_at, id))
+DO $$
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM pg_indexes WHERE indexname = 'idx_payment_record_check_logs_payment_id_occurred_at') THEN
+CREATE INDEX idx_payment_record_check_logs_payment_id_occurred_at ON payment_record_check_logs(payment_id, occurred_at, id);
+END IF;
+END$$;
\ No newline at end of file
@@ -0,0 +1,38 @@
+DROP TABLE IF EXISTS payment_record_check_log_archives;
+
+-- Kembalikan ke tabel biasa; partisi yang sudah di-detach job retention tidak ikut dikembalikan
+ALTER TABLE payment_record_check_logs RENAME TO payment_record_check_logs_partitioned;
+DROP INDEX IF EXISTS idx_payment_record_check_logs_payment_id;
+DROP INDEX IF EXISTS idx_payment_record_check_logs_occurred_at;
+DROP INDEX IF EXISTS idx_payment_record_check_logs_payment_id_occurred_at;
+ALTER TABLE payment_record_check_logs_partitioned RENAME CONSTRAINT payment_record_check_logs_pkey TO payment_record_check_logs_partitioned_pkey;
+
+CREATE TABLE payment_record_check_logs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid (),
+    payment_id UUID NOT NULL,
+    occurred_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    method TEXT NOT NULL,
+    url TEXT NOT NULL,
+    request_headers JSONB,
+    request_body BYTEA,
+    response_headers JSONB,
+    response_body BYTEA,
+    status_code INT,
+    delay_seconds INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP
+    );
+
+INSERT INTO payment_record_check_logs (
+    id, payment_id, occurred_at, method, url, request_headers, request_body, response_headers, response_body,
+    status_code, delay_seconds, created_at, updated_at, deleted_at)
+SELECT id, payment_id, occurred_at, method, url, request_headers, request_body, response_headers, response_body,
+    status_code, delay_seconds, created_at, updated_at, deleted_at
+FROM payment_record_check_logs_partitioned;
+
+DROP TABLE payment_record_check_logs_partitioned;
+
+CREATE INDEX idx_payment_record_check_logs_payment_id ON payment_record_check_logs(payment_id);
+CREATE INDEX idx_payment_record_check_logs_occurred_at ON payment_record_check_logs(occurred_at);
+CREATE INDEX idx_payment_record_check_logs_payment_id_occurred_at ON payment_record_check_logs(payment_id, occurred_at, id);
\ No newline at end of file
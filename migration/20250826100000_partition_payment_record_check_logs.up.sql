@@ -0,0 +1,80 @@
+-- payment_record_check_logs → partisi bulanan (RANGE occurred_at) supaya data lama bisa di-drop per partisi.
+-- Primary key partitioned table wajib memuat kolom partisi → (id, occurred_at).
+ALTER TABLE payment_record_check_logs RENAME TO payment_record_check_logs_legacy;
+ALTER TABLE payment_record_check_logs_legacy RENAME CONSTRAINT payment_record_check_logs_pkey TO payment_record_check_logs_legacy_pkey;
+DROP INDEX IF EXISTS idx_payment_record_check_logs_payment_id;
+DROP INDEX IF EXISTS idx_payment_record_check_logs_occurred_at;
+DROP INDEX IF EXISTS idx_payment_record_check_logs_payment_id_occurred_at;
+
+CREATE TABLE payment_record_check_logs (
+    id UUID NOT NULL DEFAULT gen_random_uuid (),
+    payment_id UUID NOT NULL,
+    occurred_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    method TEXT NOT NULL,
+    url TEXT NOT NULL,
+    request_headers JSONB,
+    request_body BYTEA,
+    response_headers JSONB,
+    response_body BYTEA,
+    status_code INT,
+    delay_seconds INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP,
+    PRIMARY KEY (id, occurred_at)
+    ) PARTITION BY RANGE (occurred_at);
+
+CREATE INDEX idx_payment_record_check_logs_payment_id ON payment_record_check_logs(payment_id);
+CREATE INDEX idx_payment_record_check_logs_occurred_at ON payment_record_check_logs(occurred_at);
+CREATE INDEX idx_payment_record_check_logs_payment_id_occurred_at ON payment_record_check_logs(payment_id, occurred_at, id);
+
+-- Penampung row di luar partisi yang sudah dibuat (mis. job retention telat membuat partisi bulan depan)
+CREATE TABLE payment_record_check_logs_default PARTITION OF payment_record_check_logs DEFAULT;
+
+-- Partisi dari bulan data tertua sampai 2 bulan ke depan; selanjutnya dibuat oleh job retention
+DO $$
+DECLARE
+  m DATE;
+  last_month DATE := (date_trunc('month', CURRENT_DATE) + INTERVAL '2 months')::date;
+BEGIN
+  SELECT COALESCE(date_trunc('month', MIN(occurred_at)), date_trunc('month', CURRENT_DATE))::date INTO m
+  FROM payment_record_check_logs_legacy;
+  WHILE m <= last_month LOOP
+    EXECUTE format('CREATE TABLE IF NOT EXISTS %I PARTITION OF payment_record_check_logs FOR VALUES FROM (%L) TO (%L)',
+      'payment_record_check_logs_p' || to_char(m, 'YYYYMM'), m, (m + INTERVAL '1 month')::date);
+    m := (m + INTERVAL '1 month')::date;
+  END LOOP;
+END$$;
+
+INSERT INTO payment_record_check_logs (
+    id, payment_id, occurred_at, method, url, request_headers, request_body, response_headers, response_body,
+    status_code, delay_seconds, created_at, updated_at, deleted_at)
+SELECT id, payment_id, occurred_at, method, url, request_headers, request_body, response_headers, response_body,
+    status_code, delay_seconds, created_at, updated_at, deleted_at
+FROM payment_record_check_logs_legacy;
+
+DROP TABLE payment_record_check_logs_legacy;
+
+-- Percobaan terakhir payment final disimpan lebih lama dari retention check log biasa
+CREATE TABLE IF NOT EXISTS payment_record_check_log_archives (
+    payment_id UUID PRIMARY KEY,
+    check_log_id UUID NOT NULL,
+    final_status TEXT NOT NULL,
+    occurred_at TIMESTAMP NOT NULL,
+    method TEXT NOT NULL,
+    url TEXT NOT NULL,
+    request_headers JSONB,
+    request_body BYTEA,
+    response_headers JSONB,
+    response_body BYTEA,
+    status_code INT,
+    delay_seconds INT NOT NULL DEFAULT 0,
+    archived_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+    );
+
+DO $$
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM pg_indexes WHERE indexname = 'idx_payment_record_check_log_archives_occurred_at') THEN
+CREATE INDEX idx_payment_record_check_log_archives_occurred_at ON payment_record_check_log_archives(occurred_at);
+END IF;
+END$$;
\ No newline at end of file